@@ -0,0 +1,83 @@
+package mongolib
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Accumulator computes an output field of a Group stage from the documents
+// of each group.
+type Accumulator struct {
+	field    string
+	operator string
+	value    interface{}
+}
+
+func (a Accumulator) element() bson.E {
+	return bson.E{Key: a.field, Value: bson.D{{Key: a.operator, Value: a.value}}}
+}
+
+func accumulators(accs []Accumulator) bson.D {
+	fields := make(bson.D, 0, len(accs))
+	for _, acc := range accs {
+		fields = append(fields, acc.element())
+	}
+	return fields
+}
+
+// Sum stores in field the sum of value over the group.
+func Sum(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$sum", value: value}
+}
+
+// Count stores in field the number of documents in the group.
+func Count(field string) Accumulator {
+	return Accumulator{field: field, operator: "$sum", value: 1}
+}
+
+func Avg(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$avg", value: value}
+}
+
+func Min(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$min", value: value}
+}
+
+func Max(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$max", value: value}
+}
+
+// First stores in field the value of the first document of the group, which
+// is only meaningful after a Sort stage.
+func First(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$first", value: value}
+}
+
+// Last stores in field the value of the last document of the group, which
+// is only meaningful after a Sort stage.
+func Last(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$last", value: value}
+}
+
+// Push stores in field an array of value for every document of the group.
+func Push(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$push", value: value}
+}
+
+// AddToSet stores in field an array of the distinct values of value in the
+// group.
+func AddToSet(field string, value interface{}) Accumulator {
+	return Accumulator{field: field, operator: "$addToSet", value: value}
+}
+
+// CompositeKey groups by several fields. Each field is named after its path
+// with dots replaced by underscores, so CompositeKey("car.color", "age")
+// yields the group _id {car_color: ..., age: ...}.
+func CompositeKey(fields ...string) bson.D {
+	key := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		key = append(key, bson.E{Key: strings.ReplaceAll(field, ".", "_"), Value: Field(field)})
+	}
+	return key
+}
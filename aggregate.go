@@ -2,66 +2,230 @@ package mongolib
 
 import (
 	"context"
+	"errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"sort"
+)
+
+var (
+	ErrNoCollection = errors.New("pipeline has no collection")
 )
 
 type Aggregate struct {
-	coll *Collection
+	coll     *Collection
 	pipeline mongo.Pipeline
+
+	notFoundIfEmpty bool
 }
 
-func (a Aggregate) Match(filter filter) Aggregate {
-	a.pipeline = append(a.pipeline, bson.D{{"$match", bson.D{{"$and", filter}}}})
-	return a
+// NewPipeline starts a pipeline that is not bound to a collection, to be
+// used as a building block such as Query.UpdatePipeline. Executing it
+// fails with ErrNoCollection.
+func NewPipeline() Aggregate {
+	return Aggregate{
+		pipeline: mongo.Pipeline{},
+	}
+}
+
+func (a Aggregate) Match(filter Filter) Aggregate {
+	return a.with(bson.D{{"$match", filter.document()}})
+}
+
+// Group groups documents by id and computes the accumulators for each group.
+// The id can be a single expression such as Field("car.color"), a
+// CompositeKey, or nil to group every document together.
+func (a Aggregate) Group(id interface{}, output ...Accumulator) Aggregate {
+	group := append(bson.D{{Key: "_id", Value: id}}, accumulators(output)...)
+	return a.with(bson.D{{Key: "$group", Value: group}})
+}
+
+// Facet runs each sub-pipeline, built with NewPipeline, over the same input
+// documents and outputs a single document holding the results of each under
+// its name.
+func (a Aggregate) Facet(facets map[string]Aggregate) Aggregate {
+	names := make([]string, 0, len(facets))
+	for name := range facets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	facet := bson.D{}
+	for _, name := range names {
+		facet = append(facet, bson.E{Key: name, Value: facets[name].pipeline})
+	}
+	return a.with(bson.D{{Key: "$facet", Value: facet}})
+}
+
+// Bucket groups documents by which of the sorted boundaries their groupBy
+// value falls between. Documents outside the boundaries go to defaultBucket,
+// which may be nil when every value is known to fall inside. Without output
+// accumulators each bucket holds a count.
+func (a Aggregate) Bucket(groupBy interface{}, boundaries []interface{}, defaultBucket interface{}, output ...Accumulator) Aggregate {
+	bucket := bson.D{
+		{Key: "groupBy", Value: groupBy},
+		{Key: "boundaries", Value: boundaries},
+	}
+	if defaultBucket != nil {
+		bucket = append(bucket, bson.E{Key: "default", Value: defaultBucket})
+	}
+	if len(output) > 0 {
+		bucket = append(bucket, bson.E{Key: "output", Value: accumulators(output)})
+	}
+	return a.with(bson.D{{Key: "$bucket", Value: bucket}})
+}
+
+// BucketAuto groups documents into the given number of buckets with evenly
+// distributed groupBy values. Granularity names a preferred number series
+// such as "R5" or "1-2-5" for the boundaries, or is empty for none.
+func (a Aggregate) BucketAuto(groupBy interface{}, buckets int, granularity string, output ...Accumulator) Aggregate {
+	bucket := bson.D{
+		{Key: "groupBy", Value: groupBy},
+		{Key: "buckets", Value: buckets},
+	}
+	if len(output) > 0 {
+		bucket = append(bucket, bson.E{Key: "output", Value: accumulators(output)})
+	}
+	if granularity != "" {
+		bucket = append(bucket, bson.E{Key: "granularity", Value: granularity})
+	}
+	return a.with(bson.D{{Key: "$bucketAuto", Value: bucket}})
 }
 
 func (a Aggregate) Sort(key string, order int) Aggregate {
-	a.pipeline = append(a.pipeline, bson.D{{"$sort", bson.D{{key, order}}}})
-	return a
+	return a.with(bson.D{{"$sort", bson.D{{key, order}}}})
+}
+
+// SortByTextScore sorts by relevance of a Text search in a preceding Match
+// stage, most relevant first, and stores the score in field.
+func (a Aggregate) SortByTextScore(field string) Aggregate {
+	score := bson.D{{Key: "$meta", Value: "textScore"}}
+	return a.
+		with(bson.D{{Key: "$addFields", Value: bson.D{{Key: field, Value: score}}}}).
+		with(bson.D{{Key: "$sort", Value: bson.D{{Key: field, Value: score}}}})
+}
+
+// GeoNearOptions configures a GeoNear stage. Zero values are left unset.
+type GeoNearOptions struct {
+	Key         string
+	MaxDistance float64
+	MinDistance float64
+	Query       Filter
+}
+
+// GeoNear orders documents nearest first from point and stores the distance
+// in meters in distanceField. It must be the first stage of the pipeline.
+func (a Aggregate) GeoNear(point Point, distanceField string, opts ...GeoNearOptions) Aggregate {
+	geoNear := bson.D{
+		{Key: "near", Value: point},
+		{Key: "distanceField", Value: distanceField},
+		{Key: "spherical", Value: true},
+	}
+	for _, opt := range opts {
+		if opt.Key != "" {
+			geoNear = append(geoNear, bson.E{Key: "key", Value: opt.Key})
+		}
+		if opt.MaxDistance > 0 {
+			geoNear = append(geoNear, bson.E{Key: "maxDistance", Value: opt.MaxDistance})
+		}
+		if opt.MinDistance > 0 {
+			geoNear = append(geoNear, bson.E{Key: "minDistance", Value: opt.MinDistance})
+		}
+		if len(opt.Query) > 0 {
+			geoNear = append(geoNear, bson.E{Key: "query", Value: opt.Query.document()})
+		}
+	}
+	return a.with(bson.D{{Key: "$geoNear", Value: geoNear}})
 }
 
 func (a Aggregate) Limit(limit int) Aggregate {
-	a.pipeline = append(a.pipeline, bson.D{{"$limit", limit}})
-	return a
+	return a.with(bson.D{{"$limit", limit}})
 }
 
 func (a Aggregate) Offset(offset int) Aggregate {
-	a.pipeline = append(a.pipeline, bson.D{{"$skip", offset}})
-	return a
+	return a.with(bson.D{{"$skip", offset}})
 }
 
 func (a Aggregate) Lookup(from, localField, foreignField, as string) Aggregate {
-	a.pipeline = append(a.pipeline, bson.D{{"$lookup", bson.D{
+	return a.with(bson.D{{"$lookup", bson.D{
 		{"from", from},
 		{"localField", localField},
 		{"foreignField", foreignField},
 		{"as", as},
 	}}})
-	return a
 }
 
 func (a Aggregate) Unwind(field string) Aggregate {
-	a.pipeline = append(a.pipeline, bson.D{{"$unwind", field}})
-	return a
+	return a.with(bson.D{{"$unwind", field}})
 }
 
 func (a Aggregate) AddField(fieldName string, value interface{}) Aggregate {
-	a.pipeline = append(a.pipeline, bson.D{{"$addFields", bson.D{{fieldName, value}}}})
+	return a.with(bson.D{{"$addFields", bson.D{{fieldName, value}}}})
+}
+
+// Project reshapes each document to the included, excluded and computed
+// fields of projection.
+func (a Aggregate) Project(projection Projection) Aggregate {
+	return a.with(bson.D{{Key: "$project", Value: projection}})
+}
+
+// Set adds or replaces field with value. It is the $set alias of AddField
+// and needs MongoDB 4.2.
+func (a Aggregate) Set(field string, value interface{}) Aggregate {
+	return a.with(bson.D{{Key: "$set", Value: bson.D{{Key: field, Value: value}}}})
+}
+
+// Unset removes fields from the documents. It needs MongoDB 4.2.
+func (a Aggregate) Unset(fields ...string) Aggregate {
+	return a.with(bson.D{{Key: "$unset", Value: fields}})
+}
+
+// ReplaceRoot replaces each document with newRoot, usually an embedded
+// document such as Field("car").
+func (a Aggregate) ReplaceRoot(newRoot interface{}) Aggregate {
+	return a.with(bson.D{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: newRoot}}}})
+}
+
+// ReplaceWith is the shorter form of ReplaceRoot. It needs MongoDB 4.2.
+func (a Aggregate) ReplaceWith(replacement interface{}) Aggregate {
+	return a.with(bson.D{{Key: "$replaceWith", Value: replacement}})
+}
+
+// with returns a copy of a with stage appended. The receiver is never
+// modified, so that pipelines derived from the same base stay independent.
+func (a Aggregate) with(stage bson.D) Aggregate {
+	pipeline := make(mongo.Pipeline, 0, len(a.pipeline)+1)
+	pipeline = append(pipeline, a.pipeline...)
+	a.pipeline = append(pipeline, stage)
 	return a
 }
 
-func (a Aggregate) Exec(ctx context.Context) Result {
+// NotFoundIfEmpty makes Exec report ErrNotFound when the pipeline returned no
+// document, instead of decoding an empty slice.
+func (a Aggregate) NotFoundIfEmpty() Aggregate {
+	a.notFoundIfEmpty = true
+	return a
+}
+
+func (a Aggregate) Exec(ctx context.Context) *MultipleResult {
+	if a.coll == nil {
+		return &MultipleResult{
+			Cursor: nil,
+			Error:  ErrNoCollection,
+		}
+	}
+
 	cur, err := a.coll.Collection.Aggregate(ctx, a.pipeline)
 	if err != nil {
 		return &MultipleResult{
 			Cursor: nil,
-			Error: err,
+			Error:  classify(err),
 		}
 	}
 
 	return &MultipleResult{
-		Cursor: cur,
-		Error:  nil,
+		Cursor:          cur,
+		Error:           nil,
+		NotFoundIfEmpty: a.notFoundIfEmpty,
 	}
 }
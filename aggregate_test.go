@@ -0,0 +1,405 @@
+package mongolib
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"testing"
+)
+
+func TestAggregate_Group(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx    = context.Background()
+		coll   = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "Trevor", Age: 27, Car: car{Color: "red", Speed: 10}},
+			{ID: NewObjectID(), Name: "Ali", Age: 31, Car: car{Color: "blue", Speed: 20}},
+			{ID: NewObjectID(), Name: "Budi", Age: 19, Car: car{Color: "red", Speed: 30}},
+			{ID: NewObjectID(), Name: "Dewi", Age: 27, Car: car{Color: "red", Speed: 40}},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: group by field with accumulators",
+			assert: func() {
+				var result []struct {
+					Color    string   `bson:"_id"`
+					Count    int      `bson:"count"`
+					TotalAge int      `bson:"totalAge"`
+					AvgSpeed float64  `bson:"avgSpeed"`
+					MinAge   int      `bson:"minAge"`
+					MaxAge   int      `bson:"maxAge"`
+					First    string   `bson:"first"`
+					Last     string   `bson:"last"`
+					Names    []string `bson:"names"`
+					Ages     []int    `bson:"ages"`
+				}
+				err := coll.Aggregate().
+					Sort("name", Ascending).
+					Group(Field("car.color"),
+						Count("count"),
+						Sum("totalAge", Field("age")),
+						Avg("avgSpeed", Field("car.speed")),
+						Min("minAge", Field("age")),
+						Max("maxAge", Field("age")),
+						First("first", Field("name")),
+						Last("last", Field("name")),
+						Push("names", Field("name")),
+						AddToSet("ages", Field("age")),
+					).
+					Sort("_id", Descending).
+					Exec(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, 2)
+
+				red := result[0]
+				assert.Equal(t, "red", red.Color)
+				assert.Equal(t, 3, red.Count)
+				assert.Equal(t, 73, red.TotalAge)
+				assert.Equal(t, float64(80)/3, red.AvgSpeed)
+				assert.Equal(t, 19, red.MinAge)
+				assert.Equal(t, 27, red.MaxAge)
+				assert.Equal(t, "Budi", red.First)
+				assert.Equal(t, "Trevor", red.Last)
+				assert.Equal(t, []string{"Budi", "Dewi", "Trevor"}, red.Names)
+				assert.ElementsMatch(t, []int{19, 27}, red.Ages)
+
+				blue := result[1]
+				assert.Equal(t, "blue", blue.Color)
+				assert.Equal(t, 1, blue.Count)
+				assert.Equal(t, []string{"Ali"}, blue.Names)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: group by composite key",
+			assert: func() {
+				var result []struct {
+					ID struct {
+						Color string `bson:"car_color"`
+						Age   int    `bson:"age"`
+					} `bson:"_id"`
+					Count int `bson:"count"`
+				}
+				err := coll.Aggregate().
+					Group(CompositeKey("car.color", "age"), Count("count")).
+					Sort("count", Descending).
+					Exec(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, 3)
+				assert.Equal(t, "red", result[0].ID.Color)
+				assert.Equal(t, 27, result[0].ID.Age)
+				assert.Equal(t, 2, result[0].Count)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: group every document",
+			assert: func() {
+				var result []struct {
+					Total int `bson:"total"`
+				}
+				err := coll.Aggregate().
+					Match(NewFilter().GreaterThan("age", 20)).
+					Group(nil, Sum("total", Field("car.speed"))).
+					Exec(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, 1)
+				assert.Equal(t, 70, result[0].Total)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestAggregate_Project(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx    = context.Background()
+		coll   = db.Coll(collName)
+		trevor = person{
+			ID:   NewObjectID(),
+			Name: "Trevor",
+			Age:  27,
+			Car:  car{Color: "red", Speed: 10},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: project included and computed fields",
+			assert: func() {
+				type response struct {
+					Name  string `bson:"name"`
+					Label string `bson:"label"`
+					Fast  bool   `bson:"fast"`
+				}
+				var result []response
+				err := coll.Aggregate().
+					Project(NewProjection().
+						Exclude("_id").
+						Include("name").
+						Compute("label", Concat(Field("name"), " (", Field("car.color"), ")")).
+						Compute("fast", Gt(Field("car.speed"), 5))).
+					Exec(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []response{{Name: "Trevor", Label: "Trevor (red)", Fast: true}}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: replace root with embedded document",
+			assert: func() {
+				var result []car
+				err := coll.Aggregate().
+					ReplaceRoot(Field("car")).
+					Exec(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []car{trevor.Car}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.Save(ctx, trevor.ID, trevor)
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestAggregate_Facet(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx    = context.Background()
+		coll   = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "Trevor", Age: 27, Car: car{Color: "red"}},
+			{ID: NewObjectID(), Name: "Ali", Age: 31, Car: car{Color: "blue"}},
+			{ID: NewObjectID(), Name: "Budi", Age: 19, Car: car{Color: "red"}},
+			{ID: NewObjectID(), Name: "Dewi", Age: 65, Car: car{Color: "red"}},
+		}
+	)
+
+	type bucket struct {
+		ID    interface{} `bson:"_id"`
+		Count int         `bson:"count"`
+		Names []string    `bson:"names"`
+	}
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: facet with group and bucket",
+			assert: func() {
+				var result []struct {
+					Colors []struct {
+						Color string `bson:"_id"`
+						Count int    `bson:"count"`
+					} `bson:"colors"`
+					Ages []bucket `bson:"ages"`
+				}
+				err := coll.Aggregate().
+					Match(NewFilter().GreaterThan("age", 18)).
+					Facet(map[string]Aggregate{
+						"colors": NewPipeline().
+							Group(Field("car.color"), Count("count")).
+							Sort("count", Descending),
+						"ages": NewPipeline().
+							Sort("name", Ascending).
+							Bucket(Field("age"), []interface{}{18, 30, 60}, "other",
+								Count("count"),
+								Push("names", Field("name")),
+							),
+					}).
+					Exec(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, 1)
+
+				assert.Len(t, result[0].Colors, 2)
+				assert.Equal(t, "red", result[0].Colors[0].Color)
+				assert.Equal(t, 3, result[0].Colors[0].Count)
+
+				assert.Equal(t, []bucket{
+					{ID: int32(18), Count: 2, Names: []string{"Budi", "Trevor"}},
+					{ID: int32(30), Count: 1, Names: []string{"Ali"}},
+					{ID: "other", Count: 1, Names: []string{"Dewi"}},
+				}, result[0].Ages)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: bucket auto",
+			assert: func() {
+				var result []struct {
+					ID struct {
+						Min int `bson:"min"`
+						Max int `bson:"max"`
+					} `bson:"_id"`
+					Count int `bson:"count"`
+				}
+				err := coll.Aggregate().
+					BucketAuto(Field("age"), 2, "").
+					Exec(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, 2)
+				assert.Equal(t, 19, result[0].ID.Min)
+				assert.Equal(t, 2, result[0].Count)
+				assert.Equal(t, 2, result[1].Count)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestAggregate_GeoNear(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx    = context.Background()
+		coll   = db.Coll(collName)
+		monas  = place{ID: NewObjectID(), Name: "Monas", Location: Point{Longitude: 106.8272, Latitude: -6.1754}}
+		kota   = place{ID: NewObjectID(), Name: "Kota Tua", Location: Point{Longitude: 106.8133, Latitude: -6.1352}}
+		bogor  = place{ID: NewObjectID(), Name: "Bogor", Location: Point{Longitude: 106.7972, Latitude: -6.5950}}
+		places = []place{monas, kota, bogor}
+	)
+
+	type result struct {
+		Name     string  `bson:"name"`
+		Distance float64 `bson:"distance"`
+	}
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: order by distance",
+			assert: func() {
+				var results []result
+				err := coll.Aggregate().
+					GeoNear(monas.Location, "distance").
+					Exec(ctx).Consume(ctx, &results)
+				assert.NoError(t, err)
+				assert.Len(t, results, 3)
+				assert.Equal(t, []string{"Monas", "Kota Tua", "Bogor"},
+					[]string{results[0].Name, results[1].Name, results[2].Name})
+				assert.Equal(t, float64(0), results[0].Distance)
+				assert.True(t, results[1].Distance > 4000 && results[1].Distance < 6000)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: filter by distance and query",
+			assert: func() {
+				var results []result
+				err := coll.Aggregate().
+					GeoNear(monas.Location, "distance", GeoNearOptions{
+						Key:         "location",
+						MaxDistance: 100000,
+						MinDistance: 1000,
+						Query:       NewFilter().NotEqual("name", "Kota Tua"),
+					}).
+					Exec(ctx).Consume(ctx, &results)
+				assert.NoError(t, err)
+				assert.Len(t, results, 1)
+				assert.Equal(t, "Bogor", results[0].Name)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.Create2dsphereIndex(ctx, "location")
+			assert.NoError(t, err)
+			for _, p := range places {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
@@ -12,30 +12,72 @@ type Collection struct {
 	*mongo.Collection
 }
 
-func (coll *Collection) FindByID(ctx context.Context, id primitive.ObjectID) Result {
+func (coll *Collection) FindByID(ctx context.Context, id primitive.ObjectID, projection ...Projection) Result {
 	filter := bson.D{{"_id", id}}
-	res := coll.FindOne(ctx, filter)
+	opt := options.FindOne()
+	if len(projection) > 0 {
+		fields := NewProjection()
+		for _, p := range projection {
+			fields = append(fields, p...)
+		}
+		opt = opt.SetProjection(fields)
+	}
+	res := coll.FindOne(ctx, filter, opt)
 
 	return &SingleResult{
 		SingleResult: res,
 	}
 }
 
-func (coll *Collection) Save(ctx context.Context, id primitive.ObjectID, data interface{}) error {
+func (coll *Collection) Save(ctx context.Context, id primitive.ObjectID, data interface{}) (WriteResult, error) {
 	update := bson.D{{"$set", data}}
 	filter := bson.D{{"_id", id}}
 	opt := options.Update().SetUpsert(true)
 
-	if _, err := coll.UpdateOne(ctx, filter, update, opt); err != nil {
-		return err
+	res, err := coll.UpdateOne(ctx, filter, update, opt)
+	if err != nil {
+		return WriteResult{}, classify(err)
+	}
+	return WriteResult{
+		Matched:    res.MatchedCount,
+		Modified:   res.ModifiedCount,
+		Upserted:   res.UpsertedCount,
+		UpsertedID: res.UpsertedID,
+	}, nil
+}
+
+// CreateTextIndex creates the text index used by Text searches over keys and
+// returns its name.
+func (coll *Collection) CreateTextIndex(ctx context.Context, keys ...string) (string, error) {
+	index := bson.D{}
+	for _, key := range keys {
+		index = append(index, bson.E{Key: key, Value: "text"})
 	}
-	return nil
+	return coll.createIndex(ctx, index)
+}
+
+// Create2dsphereIndex creates a 2dsphere index over keys, needed by Near
+// filters and GeoNear stages, and returns its name.
+func (coll *Collection) Create2dsphereIndex(ctx context.Context, keys ...string) (string, error) {
+	index := bson.D{}
+	for _, key := range keys {
+		index = append(index, bson.E{Key: key, Value: "2dsphere"})
+	}
+	return coll.createIndex(ctx, index)
+}
+
+func (coll *Collection) createIndex(ctx context.Context, keys bson.D) (string, error) {
+	name, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys})
+	if err != nil {
+		return "", classify(err)
+	}
+	return name, nil
 }
 
 func (coll *Collection) Query() Query {
 	return Query{
 		coll:   coll,
-		filter: bson.A{},
+		filter: NewFilter(),
 		limit:  0,
 		offset: 0,
 		sort:   bson.D{},
@@ -47,4 +89,4 @@ func (coll *Collection) Aggregate() Aggregate {
 		coll:     coll,
 		pipeline: mongo.Pipeline{},
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,160 @@
+package mongolib
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+var (
+	ErrDuplicateKey  = errors.New("duplicate key")
+	ErrTimeout       = errors.New("operation timed out")
+	ErrWriteConflict = errors.New("write conflict")
+	ErrNetwork       = errors.New("network error")
+	ErrValidation    = errors.New("document failed validation")
+)
+
+// Server error codes, see
+// https://github.com/mongodb/mongo/blob/master/src/mongo/base/error_codes.yml
+const (
+	codeMaxTimeMSExpired          = 50
+	codeWriteConcernFailed        = 64
+	codeWriteConflict             = 112
+	codeDocumentValidationFailure = 121
+	codeDuplicateKey              = 11000
+	codeDuplicateKeyUpdate        = 11001
+	codeDuplicateKeyPrefix        = 12582
+)
+
+// DuplicateKeyError is returned when a write violates a unique index. It
+// matches ErrDuplicateKey with errors.Is, and the driver error it wraps is
+// available through errors.As.
+type DuplicateKeyError struct {
+	// Index is the name of the violated index.
+	Index string
+	// Key is the offending key as reported by the server, for example
+	// `{ name: "Trevor" }`.
+	Key string
+	err error
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *DuplicateKeyError) Is(target error) bool {
+	return target == ErrDuplicateKey
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return e.err
+}
+
+// classifiedError tags a driver error with one of the exported sentinels,
+// keeping the driver error reachable through errors.As.
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+var duplicateKeyMessage = regexp.MustCompile(`index: (\S+) dup key: (\{.*\})`)
+
+// classify wraps err so that it matches the exported sentinel for its kind.
+// Errors of no known kind are returned unchanged.
+func classify(err error) error {
+	if err == nil || err == ErrNotFound {
+		return err
+	}
+	if _, ok := err.(*classifiedError); ok {
+		return err
+	}
+	if _, ok := err.(*DuplicateKeyError); ok {
+		return err
+	}
+
+	var (
+		cmdErr   mongo.CommandError
+		writeErr mongo.WriteException
+		bulkErr  mongo.BulkWriteException
+		connErr  topology.ConnectionError
+		netErr   net.Error
+	)
+	switch {
+	case errors.As(err, &writeErr):
+		for _, we := range writeErr.WriteErrors {
+			if kind := classifyCode(int32(we.Code), we.Message, err); kind != nil {
+				return kind
+			}
+		}
+		if wce := writeErr.WriteConcernError; wce != nil {
+			if kind := classifyCode(int32(wce.Code), wce.Message, err); kind != nil {
+				return kind
+			}
+		}
+		if writeErr.HasErrorLabel("NetworkError") {
+			return &classifiedError{kind: ErrNetwork, err: err}
+		}
+	case errors.As(err, &bulkErr):
+		for _, we := range bulkErr.WriteErrors {
+			if kind := classifyCode(int32(we.Code), we.Message, err); kind != nil {
+				return kind
+			}
+		}
+		if wce := bulkErr.WriteConcernError; wce != nil {
+			if kind := classifyCode(int32(wce.Code), wce.Message, err); kind != nil {
+				return kind
+			}
+		}
+	case errors.As(err, &cmdErr):
+		if kind := classifyCode(cmdErr.Code, cmdErr.Message, err); kind != nil {
+			return kind
+		}
+		if cmdErr.HasErrorLabel("NetworkError") {
+			return &classifiedError{kind: ErrNetwork, err: err}
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &classifiedError{kind: ErrTimeout, err: err}
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return &classifiedError{kind: ErrTimeout, err: err}
+	case errors.Is(err, topology.ErrServerSelectionTimeout), errors.As(err, &connErr), errors.As(err, &netErr):
+		return &classifiedError{kind: ErrNetwork, err: err}
+	}
+	return err
+}
+
+func classifyCode(code int32, message string, err error) error {
+	switch code {
+	case codeDuplicateKey, codeDuplicateKeyUpdate, codeDuplicateKeyPrefix:
+		dup := &DuplicateKeyError{err: err}
+		if m := duplicateKeyMessage.FindStringSubmatch(message); m != nil {
+			dup.Index, dup.Key = m[1], m[2]
+		}
+		return dup
+	case codeMaxTimeMSExpired, codeWriteConcernFailed:
+		return &classifiedError{kind: ErrTimeout, err: err}
+	case codeWriteConflict:
+		return &classifiedError{kind: ErrWriteConflict, err: err}
+	case codeDocumentValidationFailure:
+		return &classifiedError{kind: ErrValidation, err: err}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package mongolib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	duplicate := mongo.WriteException{
+		WriteErrors: mongo.WriteErrors{{
+			Code:    11000,
+			Message: `E11000 duplicate key error collection: db.coll index: name_1 dup key: { name: "Trevor" }`,
+		}},
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "duplicate key write error",
+			err:  duplicate,
+			want: ErrDuplicateKey,
+		},
+		{
+			name: "max time expired command error",
+			err:  mongo.CommandError{Code: 50, Message: "operation exceeded time limit"},
+			want: ErrTimeout,
+		},
+		{
+			name: "context deadline",
+			err:  fmt.Errorf("find: %w", context.DeadlineExceeded),
+			want: ErrTimeout,
+		},
+		{
+			name: "write conflict command error",
+			err:  mongo.CommandError{Code: 112, Message: "WriteConflict"},
+			want: ErrWriteConflict,
+		},
+		{
+			name: "network command error",
+			err:  mongo.CommandError{Message: "connection reset", Labels: []string{"NetworkError"}},
+			want: ErrNetwork,
+		},
+		{
+			name: "document validation write error",
+			err:  mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 121, Message: "Document failed validation"}}},
+			want: ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classify(tt.err)
+			assert.True(t, errors.Is(err, tt.want))
+			assert.Equal(t, tt.err.Error(), err.Error())
+		})
+	}
+
+	t.Run("duplicate key details", func(t *testing.T) {
+		var dup *DuplicateKeyError
+		assert.True(t, errors.As(classify(duplicate), &dup))
+		assert.Equal(t, "name_1", dup.Index)
+		assert.Equal(t, `{ name: "Trevor" }`, dup.Key)
+
+		var we mongo.WriteException
+		assert.True(t, errors.As(dup, &we))
+	})
+
+	t.Run("unknown error unchanged", func(t *testing.T) {
+		err := errors.New("unknown")
+		assert.Equal(t, err, classify(err))
+	})
+}
@@ -0,0 +1,198 @@
+package mongolib
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Expr is an aggregation expression. It can be used in Filter.Expr and
+// anywhere a stage accepts a value, such as Aggregate.AddField.
+//
+// Operator arguments may be other expressions or plain values. Plain strings
+// starting with "$" are read by the server as field paths; wrap them in
+// Literal to compare against the string itself.
+type Expr struct {
+	value interface{}
+}
+
+func (e Expr) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if e.value == nil {
+		return bsontype.Null, nil, nil
+	}
+	return bson.MarshalValue(e.value)
+}
+
+// Field refers to the value of a field of the current document, for example
+// Field("car.speed").
+func Field(path string) Expr {
+	return Expr{value: "$" + path}
+}
+
+// Variable refers to a system or user variable, for example Variable("ROOT").
+func Variable(name string) Expr {
+	return Expr{value: "$$" + name}
+}
+
+// Literal returns value without evaluating it as an expression.
+func Literal(value interface{}) Expr {
+	return Expr{value: bson.D{{Key: "$literal", Value: value}}}
+}
+
+func operator(op string, args ...interface{}) Expr {
+	return Expr{value: bson.D{{Key: op, Value: bson.A(args)}}}
+}
+
+func unaryOperator(op string, arg interface{}) Expr {
+	return Expr{value: bson.D{{Key: op, Value: arg}}}
+}
+
+// Comparison
+
+func Eq(a, b interface{}) Expr {
+	return operator("$eq", a, b)
+}
+
+func Ne(a, b interface{}) Expr {
+	return operator("$ne", a, b)
+}
+
+func Gt(a, b interface{}) Expr {
+	return operator("$gt", a, b)
+}
+
+func Gte(a, b interface{}) Expr {
+	return operator("$gte", a, b)
+}
+
+func Lt(a, b interface{}) Expr {
+	return operator("$lt", a, b)
+}
+
+func Lte(a, b interface{}) Expr {
+	return operator("$lte", a, b)
+}
+
+// Boolean
+
+func And(exprs ...interface{}) Expr {
+	return operator("$and", exprs...)
+}
+
+func Or(exprs ...interface{}) Expr {
+	return operator("$or", exprs...)
+}
+
+func Not(expr interface{}) Expr {
+	return operator("$not", expr)
+}
+
+// Arithmetic
+
+func Add(values ...interface{}) Expr {
+	return operator("$add", values...)
+}
+
+func Subtract(a, b interface{}) Expr {
+	return operator("$subtract", a, b)
+}
+
+func Multiply(values ...interface{}) Expr {
+	return operator("$multiply", values...)
+}
+
+func Divide(a, b interface{}) Expr {
+	return operator("$divide", a, b)
+}
+
+func Mod(a, b interface{}) Expr {
+	return operator("$mod", a, b)
+}
+
+func Abs(value interface{}) Expr {
+	return unaryOperator("$abs", value)
+}
+
+// Conditional
+
+// Cond evaluates to then when condition is true and to otherwise if not.
+func Cond(condition, then, otherwise interface{}) Expr {
+	return operator("$cond", condition, then, otherwise)
+}
+
+// IfNull evaluates to replacement when value is null or missing.
+func IfNull(value, replacement interface{}) Expr {
+	return operator("$ifNull", value, replacement)
+}
+
+// String
+
+func Concat(values ...interface{}) Expr {
+	return operator("$concat", values...)
+}
+
+func ToLower(value interface{}) Expr {
+	return unaryOperator("$toLower", value)
+}
+
+func ToUpper(value interface{}) Expr {
+	return unaryOperator("$toUpper", value)
+}
+
+// Array
+
+func ArraySize(array interface{}) Expr {
+	return unaryOperator("$size", array)
+}
+
+func ArrayElemAt(array interface{}, index int) Expr {
+	return operator("$arrayElemAt", array, index)
+}
+
+// Date
+
+func Year(date interface{}) Expr {
+	return unaryOperator("$year", date)
+}
+
+func Month(date interface{}) Expr {
+	return unaryOperator("$month", date)
+}
+
+func DayOfMonth(date interface{}) Expr {
+	return unaryOperator("$dayOfMonth", date)
+}
+
+func DayOfWeek(date interface{}) Expr {
+	return unaryOperator("$dayOfWeek", date)
+}
+
+func Hour(date interface{}) Expr {
+	return unaryOperator("$hour", date)
+}
+
+func Minute(date interface{}) Expr {
+	return unaryOperator("$minute", date)
+}
+
+func Second(date interface{}) Expr {
+	return unaryOperator("$second", date)
+}
+
+// DateToString formats date using the server's format specifiers, for
+// example "%Y-%m-%d".
+func DateToString(format string, date interface{}) Expr {
+	return unaryOperator("$dateToString", bson.D{
+		{Key: "format", Value: format},
+		{Key: "date", Value: date},
+	})
+}
+
+// DateFromString parses a date string in ISO 8601 or the given format.
+// An empty format uses the server default.
+func DateFromString(date interface{}, format string) Expr {
+	args := bson.D{{Key: "dateString", Value: date}}
+	if format != "" {
+		args = append(args, bson.E{Key: "format", Value: format})
+	}
+	return unaryOperator("$dateFromString", args)
+}
@@ -1,54 +1,315 @@
 package mongolib
 
-import "go.mongodb.org/mongo-driver/bson"
+import (
+	"regexp"
+	"strings"
 
-type filter bson.A
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
-func Filter() filter {
-	return filter{}
+// MatchOption changes how the string matching helpers compare values.
+type MatchOption string
+
+const (
+	// IgnoreCase matches regardless of letter case. Case-insensitive regexes
+	// cannot use an index efficiently.
+	IgnoreCase MatchOption = "i"
+)
+
+// Filter is a set of query conditions that are all required to match. It is
+// accepted by Query.Where and Aggregate.Match.
+type Filter bson.A
+
+func NewFilter() Filter {
+	return Filter{}
+}
+
+func (f Filter) Equal(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: value}})
+}
+
+func (f Filter) Regex(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$regex", value}}}})
+}
+
+// RegexWithOptions matches pattern with the given $options flags, such as
+// "i" or "m". The pattern is used as is; see StartsWith, EndsWith and
+// Contains for matching user input.
+func (f Filter) RegexWithOptions(key, pattern, options string) Filter {
+	return f.with(bson.D{{Key: key, Value: primitive.Regex{Pattern: pattern, Options: options}}})
+}
+
+// StartsWith matches string fields beginning with prefix. The prefix is
+// escaped, and without IgnoreCase the anchored regex can use an index.
+func (f Filter) StartsWith(key, prefix string, opts ...MatchOption) Filter {
+	return f.RegexWithOptions(key, "^"+regexp.QuoteMeta(prefix), matchOptions(opts))
+}
+
+// EndsWith matches string fields ending with suffix. The suffix is escaped,
+// and anchored with \z since $ also matches before a trailing newline.
+func (f Filter) EndsWith(key, suffix string, opts ...MatchOption) Filter {
+	return f.RegexWithOptions(key, regexp.QuoteMeta(suffix)+`\z`, matchOptions(opts))
+}
+
+// Contains matches string fields containing substr. The substring is escaped.
+func (f Filter) Contains(key, substr string, opts ...MatchOption) Filter {
+	return f.RegexWithOptions(key, regexp.QuoteMeta(substr), matchOptions(opts))
+}
+
+// EqualFold matches string fields equal to value regardless of case. For
+// frequent queries, prefer an index with a case-insensitive collation.
+func (f Filter) EqualFold(key, value string) Filter {
+	return f.RegexWithOptions(key, "^"+regexp.QuoteMeta(value)+`\z`, string(IgnoreCase))
+}
+
+func (f Filter) NotEqual(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$ne", value}}}})
+}
+
+func (f Filter) GreaterThan(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$gt", value}}}})
+}
+
+func (f Filter) GreaterThanEqual(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$gte", value}}}})
+}
+
+func (f Filter) LessThan(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$lt", value}}}})
+}
+
+func (f Filter) LessThanEqual(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$lte", value}}}})
+}
+
+func (f Filter) In(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$in", value}}}})
+}
+
+func (f Filter) NotIn(key string, value interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{"$nin", value}}}})
+}
+
+// Exists matches documents that contain the field when exists is true, and
+// documents that lack it when exists is false. A field holding null exists.
+func (f Filter) Exists(key string, exists bool) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$exists", Value: exists}}}})
+}
+
+// Type matches documents whose field holds a value of one of the given BSON
+// types. For arrays, the elements are checked as well as the array itself.
+func (f Filter) Type(key string, types ...bsontype.Type) Filter {
+	codes := bson.A{}
+	for _, t := range types {
+		codes = append(codes, int32(t))
+	}
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$type", Value: codes}}}})
+}
+
+// IsNull matches documents whose field is present and explicitly null.
+// Unlike Equal(key, nil), it does not match documents that lack the field.
+func (f Filter) IsNull(key string) Filter {
+	return f.Type(key, bsontype.Null)
+}
+
+// IsMissing matches documents that do not contain the field at all.
+// Documents whose field is null are not matched.
+func (f Filter) IsMissing(key string) Filter {
+	return f.Exists(key, false)
+}
+
+// ElemMatch matches documents whose array field contains at least one
+// element satisfying every condition of the nested filter. Conditions with an
+// empty key apply to the element itself, which is how scalar arrays are
+// matched, for example GreaterThan("", 80).LessThan("", 90).
+func (f Filter) ElemMatch(key string, filter Filter) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$elemMatch", Value: filter.elemMatch()}}}})
+}
+
+// elemMatch renders the filter as an $elemMatch document. Conditions on the
+// empty key are merged as bare operators, the others are kept in an $and.
+func (f Filter) elemMatch() bson.D {
+	operators := bson.D{}
+	fields := Filter{}
+	for _, condition := range f {
+		doc, ok := condition.(bson.D)
+		if !ok || len(doc) != 1 || doc[0].Key != "" {
+			fields = append(fields, condition)
+			continue
+		}
+		if ops, ok := doc[0].Value.(bson.D); ok {
+			operators = append(operators, ops...)
+		} else {
+			operators = append(operators, bson.E{Key: "$eq", Value: doc[0].Value})
+		}
+	}
+	if len(fields) == 0 {
+		return operators
+	}
+	return append(fields.document(), operators...)
+}
+
+// All matches documents whose array field contains every given value.
+func (f Filter) All(key string, values ...interface{}) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$all", Value: bson.A(values)}}}})
+}
+
+// Size matches documents whose array field has exactly size elements.
+func (f Filter) Size(key string, size int) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$size", Value: size}}}})
+}
+
+// TextOptions configures a $text search. Zero values leave the server
+// defaults in place.
+type TextOptions struct {
+	Language           string
+	CaseSensitive      bool
+	DiacriticSensitive bool
+}
+
+// Text matches documents against the collection's text index. A collection
+// has at most one text index, so no key is given.
+func (f Filter) Text(search string, opts ...TextOptions) Filter {
+	text := bson.D{{Key: "$search", Value: search}}
+	for _, opt := range opts {
+		if opt.Language != "" {
+			text = append(text, bson.E{Key: "$language", Value: opt.Language})
+		}
+		if opt.CaseSensitive {
+			text = append(text, bson.E{Key: "$caseSensitive", Value: true})
+		}
+		if opt.DiacriticSensitive {
+			text = append(text, bson.E{Key: "$diacriticSensitive", Value: true})
+		}
+	}
+	return f.with(bson.D{{Key: "$text", Value: text}})
+}
+
+// Near matches documents whose field is near point, ordered nearest first.
+// Distances are in meters on a 2dsphere index; a zero maxDistance or
+// minDistance leaves that bound unset.
+func (f Filter) Near(key string, point Point, maxDistance, minDistance float64) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$near", Value: near(point, maxDistance, minDistance)}}}})
+}
+
+// NearSphere is like Near but always calculates distances on a sphere.
+func (f Filter) NearSphere(key string, point Point, maxDistance, minDistance float64) Filter {
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$nearSphere", Value: near(point, maxDistance, minDistance)}}}})
+}
+
+// GeoWithin matches documents whose field lies entirely inside geometry,
+// which is usually a Polygon or MultiPolygon.
+func (f Filter) GeoWithin(key string, geometry Geometry) Filter {
+	within := bson.D{{Key: "$geometry", Value: geometry}}
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$geoWithin", Value: within}}}})
+}
+
+// GeoWithinBox matches documents whose field lies inside the flat rectangle
+// spanned by bottomLeft and upperRight.
+func (f Filter) GeoWithinBox(key string, bottomLeft, upperRight Point) Filter {
+	within := bson.D{{Key: "$box", Value: bson.A{bottomLeft.coordinates(), upperRight.coordinates()}}}
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$geoWithin", Value: within}}}})
+}
+
+// GeoWithinCenterSphere matches documents whose field lies inside the
+// spherical circle around center. The radius is in radians, that is the
+// distance divided by the earth's radius.
+func (f Filter) GeoWithinCenterSphere(key string, center Point, radius float64) Filter {
+	within := bson.D{{Key: "$centerSphere", Value: bson.A{center.coordinates(), radius}}}
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$geoWithin", Value: within}}}})
+}
+
+// GeoIntersects matches documents whose field intersects geometry.
+func (f Filter) GeoIntersects(key string, geometry Geometry) Filter {
+	intersects := bson.D{{Key: "$geometry", Value: geometry}}
+	return f.with(bson.D{{Key: key, Value: bson.D{{Key: "$geoIntersects", Value: intersects}}}})
+}
+
+// Expr matches documents for which the aggregation expression evaluates to
+// true. Unlike the other operators it can compare fields of the same
+// document, for example Gt(Field("spent"), Field("budget")).
+func (f Filter) Expr(expr Expr) Filter {
+	return f.with(bson.D{{Key: "$expr", Value: expr}})
+}
+
+// And matches documents that satisfy every given filter. Without filters the
+// clause is left out.
+func (f Filter) And(filters ...Filter) Filter {
+	return f.logical("$and", filters)
 }
 
-func (f filter) Equal(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: value}})
-	return filter
+// Or matches documents that satisfy at least one of the given filters.
+// Without filters the clause is left out.
+func (f Filter) Or(filters ...Filter) Filter {
+	return f.logical("$or", filters)
 }
 
-func (f filter) Regex(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$regex", value}}}})
-	return filter
+// Nor matches documents that satisfy none of the given filters. Without
+// filters the clause is left out.
+func (f Filter) Nor(filters ...Filter) Filter {
+	return f.logical("$nor", filters)
 }
 
-func (f filter) NotEqual(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$ne", value}}}})
-	return filter
+// Not matches documents that do not satisfy the given filter. MongoDB only
+// accepts $not on a field, so the negation of a whole filter is written as
+// a single-element $nor.
+func (f Filter) Not(filter Filter) Filter {
+	return f.Nor(filter)
 }
 
-func (f filter) GreaterThan(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$gt", value}}}})
-	return filter
+// logical adds the operator over filters, or returns f unchanged when there
+// is none, since MongoDB rejects an empty $and, $or or $nor.
+func (f Filter) logical(operator string, filters []Filter) Filter {
+	if len(filters) == 0 {
+		return f
+	}
+	return f.with(bson.D{{Key: operator, Value: documents(filters)}})
 }
 
-func (f filter) GreaterThanEqual(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$gte", value}}}})
-	return filter
+// with returns a copy of f with condition added. The receiver is never
+// modified, so that filters derived from the same base stay independent.
+func (f Filter) with(condition bson.D) Filter {
+	filter := make(Filter, 0, len(f)+1)
+	filter = append(filter, f...)
+	return append(filter, condition)
 }
 
-func (f filter) LessThan(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$lt", value}}}})
-	return filter
+// document renders the filter as a query document. An empty filter matches
+// every document, since MongoDB rejects an empty $and array.
+func (f Filter) document() bson.D {
+	if len(f) == 0 {
+		return bson.D{}
+	}
+	return bson.D{{Key: "$and", Value: bson.A(f)}}
 }
 
-func (f filter) LessThanEqual(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$lte", value}}}})
-	return filter
+func documents(filters []Filter) bson.A {
+	docs := bson.A{}
+	for _, f := range filters {
+		docs = append(docs, f.document())
+	}
+	return docs
 }
 
-func (f filter) In(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$in", value}}}})
-	return filter
+func near(point Point, maxDistance, minDistance float64) bson.D {
+	near := bson.D{{Key: "$geometry", Value: point}}
+	if maxDistance > 0 {
+		near = append(near, bson.E{Key: "$maxDistance", Value: maxDistance})
+	}
+	if minDistance > 0 {
+		near = append(near, bson.E{Key: "$minDistance", Value: minDistance})
+	}
+	return near
 }
 
-func (f filter) NotIn(key string, value interface{}) filter {
-	filter := append(f, bson.D{{Key: key, Value: bson.D{{"$nin", value}}}})
-	return filter
+func matchOptions(opts []MatchOption) string {
+	var options strings.Builder
+	for _, opt := range opts {
+		if !strings.Contains(options.String(), string(opt)) {
+			options.WriteString(string(opt))
+		}
+	}
+	return options.String()
 }
@@ -0,0 +1,119 @@
+package mongolib
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestFilter_Derived(t *testing.T) {
+	base := NewFilter().Equal("a", 1).Equal("b", 2).Equal("c", 3)
+	x := base.Equal("x", 1)
+	y := base.Equal("y", 1)
+
+	assert.Equal(t, Filter{
+		bson.D{{Key: "a", Value: 1}},
+		bson.D{{Key: "b", Value: 2}},
+		bson.D{{Key: "c", Value: 3}},
+		bson.D{{Key: "x", Value: 1}},
+	}, x)
+	assert.Equal(t, Filter{
+		bson.D{{Key: "a", Value: 1}},
+		bson.D{{Key: "b", Value: 2}},
+		bson.D{{Key: "c", Value: 3}},
+		bson.D{{Key: "y", Value: 1}},
+	}, y)
+	assert.Len(t, base, 3)
+}
+
+func TestQuery_Derived(t *testing.T) {
+	base := Query{}.
+		Where(NewFilter().Equal("a", 1).Equal("b", 2)).
+		Sort("a", Ascending).Sort("b", Ascending).Sort("c", Ascending).
+		Select("a", "b", "c")
+	x := base.Equal("x", 1).Sort("x", Descending).Select("x")
+	y := base.Equal("y", 1).Sort("y", Descending).Project(NewProjection().Include("y"))
+
+	assert.Equal(t, bson.D{{Key: "x", Value: 1}}, x.filter[2])
+	assert.Equal(t, bson.D{{Key: "y", Value: 1}}, y.filter[2])
+	assert.Equal(t, bson.E{Key: "x", Value: Descending}, x.sort[3])
+	assert.Equal(t, bson.E{Key: "y", Value: Descending}, y.sort[3])
+	assert.Equal(t, bson.E{Key: "x", Value: 1}, x.projection[3])
+	assert.Equal(t, bson.E{Key: "y", Value: 1}, y.projection[3])
+	assert.Len(t, base.filter, 2)
+	assert.Len(t, base.sort, 3)
+	assert.Len(t, base.projection, 3)
+}
+
+func TestFilter_ElemMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bson.D
+	}{
+		{
+			name:   "success: scalar elements",
+			filter: NewFilter().ElemMatch("score", NewFilter().GreaterThan("", 80).LessThan("", 90)),
+			want: bson.D{{Key: "score", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+				{Key: "$gt", Value: 80},
+				{Key: "$lt", Value: 90},
+			}}}}},
+		},
+		{
+			name:   "success: scalar element equal",
+			filter: NewFilter().ElemMatch("score", NewFilter().Equal("", 80)),
+			want: bson.D{{Key: "score", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+				{Key: "$eq", Value: 80},
+			}}}}},
+		},
+		{
+			name:   "success: subdocument fields",
+			filter: NewFilter().ElemMatch("cars", NewFilter().Equal("color", "red")),
+			want: bson.D{{Key: "cars", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+				{Key: "$and", Value: bson.A{bson.D{{Key: "color", Value: "red"}}}},
+			}}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, Filter{tt.want}, tt.filter)
+		})
+	}
+}
+
+func TestFilter_EmptyLogical(t *testing.T) {
+	base := NewFilter().Equal("a", 1)
+	assert.Equal(t, base, base.And().Or().Nor())
+	assert.Equal(t, bson.D{}, NewFilter().Or().document())
+
+	q := Query{}.Equal("a", 1).And().Or().Nor()
+	assert.Equal(t, base, q.filter)
+}
+
+func TestAggregate_Derived(t *testing.T) {
+	base := NewPipeline().Match(NewFilter().Equal("a", 1)).Sort("a", Ascending).Limit(5)
+	x := base.Group(nil, Count("n"))
+	y := base.Unwind("$score")
+
+	assert.Len(t, x.pipeline, 4)
+	assert.Len(t, y.pipeline, 4)
+	assert.Equal(t, "$group", x.pipeline[3][0].Key)
+	assert.Equal(t, "$unwind", y.pipeline[3][0].Key)
+	assert.Len(t, base.pipeline, 3)
+}
+
+func TestProjection_ElemMatch(t *testing.T) {
+	got := NewProjection().ElemMatch("score", NewFilter().GreaterThan("", 80).LessThan("", 90))
+	assert.Equal(t, Projection{{Key: "score", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+		{Key: "$gt", Value: 80},
+		{Key: "$lt", Value: 90},
+	}}}}}, got)
+}
+
+func TestFilter_MatchAnchors(t *testing.T) {
+	assert.Equal(t, Filter{bson.D{{Key: "name", Value: primitive.Regex{Pattern: `ali\z`, Options: "i"}}}},
+		NewFilter().EndsWith("name", "ali", IgnoreCase))
+	assert.Equal(t, Filter{bson.D{{Key: "name", Value: primitive.Regex{Pattern: `^bob\.\z`, Options: "i"}}}},
+		NewFilter().EqualFold("name", "bob."))
+}
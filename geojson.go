@@ -0,0 +1,165 @@
+package mongolib
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Geometry is a GeoJSON object usable in geospatial filters.
+type Geometry interface {
+	bson.Marshaler
+	geometryType() string
+}
+
+// Point is a GeoJSON point. Coordinates follow the GeoJSON order, longitude
+// first.
+type Point struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// LineString is a GeoJSON line through two or more points.
+type LineString []Point
+
+// Polygon is a GeoJSON polygon. The first ring is the exterior boundary and
+// any further rings are holes. Every ring must be closed, repeating its first
+// point at the end.
+type Polygon [][]Point
+
+// MultiPolygon is a GeoJSON collection of polygons.
+type MultiPolygon []Polygon
+
+type geoJSON struct {
+	Type        string        `bson:"type"`
+	Coordinates bson.RawValue `bson:"coordinates"`
+}
+
+func (p Point) geometryType() string        { return "Point" }
+func (l LineString) geometryType() string   { return "LineString" }
+func (p Polygon) geometryType() string      { return "Polygon" }
+func (m MultiPolygon) geometryType() string { return "MultiPolygon" }
+
+func (p Point) coordinates() []float64 {
+	return []float64{p.Longitude, p.Latitude}
+}
+
+func (l LineString) coordinates() [][]float64 {
+	coordinates := make([][]float64, 0, len(l))
+	for _, p := range l {
+		coordinates = append(coordinates, p.coordinates())
+	}
+	return coordinates
+}
+
+func (p Polygon) coordinates() [][][]float64 {
+	coordinates := make([][][]float64, 0, len(p))
+	for _, ring := range p {
+		coordinates = append(coordinates, LineString(ring).coordinates())
+	}
+	return coordinates
+}
+
+func (m MultiPolygon) coordinates() [][][][]float64 {
+	coordinates := make([][][][]float64, 0, len(m))
+	for _, p := range m {
+		coordinates = append(coordinates, p.coordinates())
+	}
+	return coordinates
+}
+
+func (p Point) MarshalBSON() ([]byte, error) {
+	return marshalGeoJSON(p.geometryType(), p.coordinates())
+}
+
+func (l LineString) MarshalBSON() ([]byte, error) {
+	return marshalGeoJSON(l.geometryType(), l.coordinates())
+}
+
+func (p Polygon) MarshalBSON() ([]byte, error) {
+	return marshalGeoJSON(p.geometryType(), p.coordinates())
+}
+
+func (m MultiPolygon) MarshalBSON() ([]byte, error) {
+	return marshalGeoJSON(m.geometryType(), m.coordinates())
+}
+
+func (p *Point) UnmarshalBSON(data []byte) error {
+	var coordinates []float64
+	if err := unmarshalGeoJSON(data, p.geometryType(), &coordinates); err != nil {
+		return err
+	}
+	if len(coordinates) != 2 {
+		return fmt.Errorf("mongolib: point must have 2 coordinates, got %d", len(coordinates))
+	}
+	*p = Point{Longitude: coordinates[0], Latitude: coordinates[1]}
+	return nil
+}
+
+func (l *LineString) UnmarshalBSON(data []byte) error {
+	var coordinates [][]float64
+	if err := unmarshalGeoJSON(data, l.geometryType(), &coordinates); err != nil {
+		return err
+	}
+	*l = lineString(coordinates)
+	return nil
+}
+
+func (p *Polygon) UnmarshalBSON(data []byte) error {
+	var coordinates [][][]float64
+	if err := unmarshalGeoJSON(data, p.geometryType(), &coordinates); err != nil {
+		return err
+	}
+	*p = polygon(coordinates)
+	return nil
+}
+
+func (m *MultiPolygon) UnmarshalBSON(data []byte) error {
+	var coordinates [][][][]float64
+	if err := unmarshalGeoJSON(data, m.geometryType(), &coordinates); err != nil {
+		return err
+	}
+	multiPolygon := make(MultiPolygon, 0, len(coordinates))
+	for _, p := range coordinates {
+		multiPolygon = append(multiPolygon, polygon(p))
+	}
+	*m = multiPolygon
+	return nil
+}
+
+func lineString(coordinates [][]float64) LineString {
+	line := make(LineString, 0, len(coordinates))
+	for _, c := range coordinates {
+		if len(c) < 2 {
+			continue
+		}
+		line = append(line, Point{Longitude: c[0], Latitude: c[1]})
+	}
+	return line
+}
+
+func polygon(coordinates [][][]float64) Polygon {
+	p := make(Polygon, 0, len(coordinates))
+	for _, ring := range coordinates {
+		p = append(p, lineString(ring))
+	}
+	return p
+}
+
+func marshalGeoJSON(geometryType string, coordinates interface{}) ([]byte, error) {
+	return bson.Marshal(bson.D{
+		{Key: "type", Value: geometryType},
+		{Key: "coordinates", Value: coordinates},
+	})
+}
+
+func unmarshalGeoJSON(data []byte, geometryType string, coordinates interface{}) error {
+	var g geoJSON
+	if err := bson.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	if g.Type != geometryType {
+		return fmt.Errorf("mongolib: expected GeoJSON %s, got %q", geometryType, g.Type)
+	}
+	return g.Coordinates.Unmarshal(coordinates)
+}
@@ -0,0 +1,187 @@
+package mongolib
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestGeometry_RoundTrip(t *testing.T) {
+	ring := []Point{{0, 0}, {3, 0}, {3, 3}, {0, 0}}
+	tests := []struct {
+		name     string
+		geometry Geometry
+		decoded  Geometry
+		want     bson.D
+	}{
+		{
+			name:     "success: point",
+			geometry: Point{Longitude: 106.8, Latitude: -6.2},
+			decoded:  &Point{},
+			want: bson.D{
+				{Key: "type", Value: "Point"},
+				{Key: "coordinates", Value: bson.A{106.8, -6.2}},
+			},
+		},
+		{
+			name:     "success: line string",
+			geometry: LineString{{0, 0}, {1, 1}},
+			decoded:  &LineString{},
+			want: bson.D{
+				{Key: "type", Value: "LineString"},
+				{Key: "coordinates", Value: bson.A{bson.A{0.0, 0.0}, bson.A{1.0, 1.0}}},
+			},
+		},
+		{
+			name:     "success: polygon",
+			geometry: Polygon{ring},
+			decoded:  &Polygon{},
+			want: bson.D{
+				{Key: "type", Value: "Polygon"},
+				{Key: "coordinates", Value: bson.A{bson.A{
+					bson.A{0.0, 0.0}, bson.A{3.0, 0.0}, bson.A{3.0, 3.0}, bson.A{0.0, 0.0},
+				}}},
+			},
+		},
+		{
+			name:     "success: multi polygon",
+			geometry: MultiPolygon{{ring}, {ring}},
+			decoded:  &MultiPolygon{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.geometry.MarshalBSON()
+			assert.NoError(t, err)
+			if tt.want != nil {
+				var got bson.D
+				assert.NoError(t, bson.Unmarshal(data, &got))
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, bson.Unmarshal(data, tt.decoded))
+			assert.Equal(t, tt.geometry, deref(tt.decoded))
+		})
+	}
+}
+
+func TestGeometry_UnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     bson.D
+		decoded interface{}
+	}{
+		{
+			name:    "failed: wrong type",
+			doc:     bson.D{{Key: "type", Value: "LineString"}, {Key: "coordinates", Value: bson.A{bson.A{0.0, 0.0}, bson.A{1.0, 1.0}}}},
+			decoded: &Point{},
+		},
+		{
+			name:    "failed: polygon as multi polygon",
+			doc:     bson.D{{Key: "type", Value: "Polygon"}, {Key: "coordinates", Value: bson.A{}}},
+			decoded: &MultiPolygon{},
+		},
+		{
+			name:    "failed: point with one coordinate",
+			doc:     bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: bson.A{1.0}}},
+			decoded: &Point{},
+		},
+		{
+			name:    "failed: point with three coordinates",
+			doc:     bson.D{{Key: "type", Value: "Point"}, {Key: "coordinates", Value: bson.A{1.0, 2.0, 3.0}}},
+			decoded: &Point{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(tt.doc)
+			assert.NoError(t, err)
+			assert.Error(t, bson.Unmarshal(data, tt.decoded))
+		})
+	}
+}
+
+func TestFilter_Near(t *testing.T) {
+	point := Point{Longitude: 1, Latitude: 2}
+	got := NewFilter().Near("location", point, 1000, 10).Equal("name", "park").document()
+	assert.Equal(t, bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "location", Value: bson.D{{Key: "$near", Value: bson.D{
+			{Key: "$geometry", Value: point},
+			{Key: "$maxDistance", Value: 1000.0},
+			{Key: "$minDistance", Value: 10.0},
+		}}}}},
+		bson.D{{Key: "name", Value: "park"}},
+	}}}, got)
+
+	got = NewFilter().NearSphere("location", point, 0, 0).document()
+	assert.Equal(t, bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "location", Value: bson.D{{Key: "$nearSphere", Value: bson.D{
+			{Key: "$geometry", Value: point},
+		}}}}},
+	}}}, got)
+}
+
+func TestAggregate_GeoNearOptions(t *testing.T) {
+	point := Point{Longitude: 1, Latitude: 2}
+	tests := []struct {
+		name      string
+		aggregate Aggregate
+		want      bson.D
+	}{
+		{
+			name:      "success: without options",
+			aggregate: NewPipeline().GeoNear(point, "distance"),
+			want: bson.D{
+				{Key: "near", Value: point},
+				{Key: "distanceField", Value: "distance"},
+				{Key: "spherical", Value: true},
+			},
+		},
+		{
+			name: "success: every option",
+			aggregate: NewPipeline().GeoNear(point, "distance", GeoNearOptions{
+				Key:         "location",
+				MaxDistance: 1000,
+				MinDistance: 10,
+				Query:       NewFilter().Equal("name", "park"),
+			}),
+			want: bson.D{
+				{Key: "near", Value: point},
+				{Key: "distanceField", Value: "distance"},
+				{Key: "spherical", Value: true},
+				{Key: "key", Value: "location"},
+				{Key: "maxDistance", Value: 1000.0},
+				{Key: "minDistance", Value: 10.0},
+				{Key: "query", Value: bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "name", Value: "park"}}}}}},
+			},
+		},
+		{
+			name:      "success: zero options left unset",
+			aggregate: NewPipeline().GeoNear(point, "distance", GeoNearOptions{}),
+			want: bson.D{
+				{Key: "near", Value: point},
+				{Key: "distanceField", Value: "distance"},
+				{Key: "spherical", Value: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, tt.aggregate.pipeline, 1)
+			assert.Equal(t, bson.D{{Key: "$geoNear", Value: tt.want}}, tt.aggregate.pipeline[0])
+		})
+	}
+}
+
+func deref(geometry Geometry) Geometry {
+	switch g := geometry.(type) {
+	case *Point:
+		return *g
+	case *LineString:
+		return *g
+	case *Polygon:
+		return *g
+	case *MultiPolygon:
+		return *g
+	}
+	return geometry
+}
@@ -0,0 +1,103 @@
+package mongolib
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Page is one page of query results along with the total number of matching
+// documents.
+type Page struct {
+	Number      int
+	Size        int
+	Total       int
+	Pages       int
+	HasNext     bool
+	HasPrevious bool
+	items       bson.RawValue
+}
+
+// Decode decodes the items of the page into v, which must be a pointer to a
+// slice.
+func (p *Page) Decode(v interface{}) error {
+	if p.items.Value == nil {
+		return decodeAll(nil, v)
+	}
+	return p.items.Unmarshal(v)
+}
+
+// Page returns the page-th page of size documents, counting from 1, along
+// with the total count. Both are computed by a single $facet aggregation, so
+// they reflect the same snapshot; the whole page must fit in one 16MB
+// document.
+func (q Query) Page(ctx context.Context, page, size int) (*Page, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("mongolib: page size must be positive, got %d", size)
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	opt := options.Aggregate()
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
+
+	cur, err := q.coll.Collection.Aggregate(ctx, q.pagePipeline(page, size), opt)
+	if err != nil {
+		return nil, classify(err)
+	}
+	var result []struct {
+		Items bson.RawValue `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cur.All(ctx, &result); err != nil {
+		return nil, classify(err)
+	}
+
+	p := &Page{
+		Number: page,
+		Size:   size,
+	}
+	if len(result) > 0 {
+		p.items = result[0].Items
+		if len(result[0].Total) > 0 {
+			p.Total = result[0].Total[0].Count
+		}
+	}
+	p.Pages = (p.Total + size - 1) / size
+	p.HasNext = page < p.Pages
+	p.HasPrevious = page > 1
+	return p, nil
+}
+
+// pagePipeline matches and sorts the documents, then splits them into the
+// requested page and the total count. Sorting inside the $facet would not be
+// able to use an index, and the count does not depend on the order.
+func (q Query) pagePipeline(page, size int) mongo.Pipeline {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: q.filter.document()}},
+	}
+	if len(q.sort) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: q.sort}})
+	}
+
+	items := mongo.Pipeline{
+		bson.D{{Key: "$skip", Value: (page - 1) * size}},
+		bson.D{{Key: "$limit", Value: size}},
+	}
+	if len(q.projection) > 0 {
+		items = append(items, bson.D{{Key: "$project", Value: q.projection}})
+	}
+
+	return append(pipeline, bson.D{{Key: "$facet", Value: bson.D{
+		{Key: "items", Value: items},
+		{Key: "total", Value: bson.A{bson.D{{Key: "$count", Value: "count"}}}},
+	}}})
+}
@@ -0,0 +1,28 @@
+package mongolib
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+)
+
+func TestQuery_PagePipeline(t *testing.T) {
+	got := Query{}.Equal("age", 27).Sort("name", Ascending).Select("name").pagePipeline(3, 10)
+	assert.Equal(t, mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "age", Value: 27}}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "name", Value: Ascending}}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "items", Value: mongo.Pipeline{
+				{{Key: "$skip", Value: 20}},
+				{{Key: "$limit", Value: 10}},
+				{{Key: "$project", Value: Projection{{Key: "name", Value: 1}}}},
+			}},
+			{Key: "total", Value: bson.A{bson.D{{Key: "$count", Value: "count"}}}},
+		}}},
+	}, got)
+
+	got = Query{}.pagePipeline(1, 5)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "$facet", got[1][0].Key)
+}
@@ -0,0 +1,273 @@
+package mongolib
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+var pageTokenKey = func() []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// SetPageTokenKey sets the key used to sign page tokens. By default a random
+// key is generated per process, so tokens are only accepted by the process
+// that issued them. The key must be at least sha256.Size bytes long. It must
+// be called before any pagination takes place, as it is not safe to call
+// concurrently with Paginator.Find.
+func SetPageTokenKey(key []byte) error {
+	if len(key) < sha256.Size {
+		return fmt.Errorf("mongolib: page token key must be at least %d bytes, got %d", sha256.Size, len(key))
+	}
+	pageTokenKey = append([]byte{}, key...)
+	return nil
+}
+
+// Paginator pages through the results of a Query by seeking past the sort
+// keys of the last seen document instead of skipping documents. The query's
+// sort keys, with _id appended as a tiebreaker, must be fields holding values
+// of a single BSON type, and must not be excluded by its projection.
+type Paginator struct {
+	query    Query
+	size     int
+	token    string
+	backward bool
+}
+
+// PageTokens holds the tokens for the pages around the one returned. An
+// empty token means there is no page in that direction.
+type PageTokens struct {
+	Next     string
+	Previous string
+}
+
+type pageToken struct {
+	Sort   bson.Raw        `bson:"s"`
+	Values []bson.RawValue `bson:"v"`
+}
+
+func (q Query) Paginate(size int) Paginator {
+	return Paginator{
+		query: q,
+		size:  size,
+	}
+}
+
+// After returns the page following the one token was issued for.
+func (p Paginator) After(token string) Paginator {
+	p.token = token
+	p.backward = false
+	return p
+}
+
+// Before returns the page preceding the one token was issued for.
+func (p Paginator) Before(token string) Paginator {
+	p.token = token
+	p.backward = true
+	return p
+}
+
+// Find decodes one page of documents into v, which must be a pointer to a
+// slice, and returns the tokens of the neighbouring pages.
+func (p Paginator) Find(ctx context.Context, v interface{}) (PageTokens, error) {
+	if p.size < 1 {
+		return PageTokens{}, fmt.Errorf("mongolib: page size must be positive, got %d", p.size)
+	}
+	sort, err := p.sort()
+	if err != nil {
+		return PageTokens{}, err
+	}
+	sortRaw, err := bson.Marshal(sort)
+	if err != nil {
+		return PageTokens{}, err
+	}
+
+	filter := append(Filter{}, p.query.filter...)
+	if p.token != "" {
+		values, err := decodePageToken(p.token, sort, sortRaw)
+		if err != nil {
+			return PageTokens{}, err
+		}
+		filter = filter.Or(seek(sort, values, p.backward)...)
+	}
+
+	order := sort
+	if p.backward {
+		order = make(bson.D, 0, len(sort))
+		for _, e := range sort {
+			order = append(order, bson.E{Key: e.Key, Value: -e.Value.(int)})
+		}
+	}
+	opt := options.Find().SetSort(order).SetLimit(int64(p.size + 1))
+	if len(p.query.projection) > 0 {
+		opt = opt.SetProjection(p.query.projection)
+	}
+	if p.query.collation != nil {
+		opt = opt.SetCollation(p.query.collation)
+	}
+
+	cur, err := p.query.coll.Find(ctx, filter.document(), opt)
+	if err != nil {
+		return PageTokens{}, classify(err)
+	}
+	var docs []bson.Raw
+	if err := cur.All(ctx, &docs); err != nil {
+		return PageTokens{}, classify(err)
+	}
+
+	more := len(docs) > p.size
+	if more {
+		docs = docs[:p.size]
+	}
+	if p.backward {
+		for i, j := 0, len(docs)-1; i < j; i, j = i+1, j-1 {
+			docs[i], docs[j] = docs[j], docs[i]
+		}
+	}
+
+	var tokens PageTokens
+	if len(docs) > 0 {
+		first, last := docs[0], docs[len(docs)-1]
+		if more && !p.backward || p.token != "" && p.backward {
+			if tokens.Next, err = encodePageToken(sort, sortRaw, last); err != nil {
+				return PageTokens{}, err
+			}
+		}
+		if more && p.backward || p.token != "" && !p.backward {
+			if tokens.Previous, err = encodePageToken(sort, sortRaw, first); err != nil {
+				return PageTokens{}, err
+			}
+		}
+	}
+
+	if err := decodeAll(docs, v); err != nil {
+		return PageTokens{}, err
+	}
+	return tokens, nil
+}
+
+func (p Paginator) sort() (bson.D, error) {
+	sort := bson.D{}
+	hasID := false
+	for _, e := range p.query.sort {
+		if _, ok := e.Value.(int); !ok {
+			return nil, fmt.Errorf("mongolib: cannot paginate on computed sort key %q", e.Key)
+		}
+		if e.Key == "_id" {
+			hasID = true
+		}
+		sort = append(sort, e)
+	}
+	if !hasID {
+		sort = append(sort, bson.E{Key: "_id", Value: Ascending})
+	}
+	return sort, nil
+}
+
+// seek builds the conditions matching documents strictly after values in
+// sort order, or strictly before them when backward is set.
+func seek(sort bson.D, values []bson.RawValue, backward bool) []Filter {
+	filters := make([]Filter, 0, len(sort))
+	for i, e := range sort {
+		f := NewFilter()
+		for j := 0; j < i; j++ {
+			f = f.Equal(sort[j].Key, values[j])
+		}
+		if (e.Value.(int) > 0) != backward {
+			f = f.GreaterThan(e.Key, values[i])
+		} else {
+			f = f.LessThan(e.Key, values[i])
+		}
+		filters = append(filters, f)
+	}
+	return filters
+}
+
+func encodePageToken(sort bson.D, sortRaw bson.Raw, doc bson.Raw) (string, error) {
+	values := make([]bson.RawValue, 0, len(sort))
+	for _, e := range sort {
+		value, err := doc.LookupErr(strings.Split(e.Key, ".")...)
+		if err != nil {
+			t, data, err := bson.MarshalValue(primitive.Null{})
+			if err != nil {
+				return "", err
+			}
+			value = bson.RawValue{Type: t, Value: data}
+		}
+		values = append(values, value)
+	}
+
+	payload, err := bson.Marshal(pageToken{Sort: sortRaw, Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signPageToken(payload)), nil
+}
+
+func decodePageToken(token string, sort bson.D, sortRaw bson.Raw) ([]bson.RawValue, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidPageToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, signPageToken(payload)) {
+		return nil, ErrInvalidPageToken
+	}
+
+	var t pageToken
+	if err := bson.Unmarshal(payload, &t); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	if !bytes.Equal(t.Sort, sortRaw) || len(t.Values) != len(sort) {
+		return nil, ErrInvalidPageToken
+	}
+	return t.Values, nil
+}
+
+func signPageToken(payload []byte) []byte {
+	mac := hmac.New(sha256.New, pageTokenKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// decodeAll decodes docs into v, which must be a pointer to a slice.
+func decodeAll(docs []bson.Raw, v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("mongolib: results argument must be a pointer to a slice, got %T", v)
+	}
+	slice := reflect.MakeSlice(ptr.Elem().Type(), 0, len(docs))
+	for _, doc := range docs {
+		elem := reflect.New(slice.Type().Elem())
+		if err := bson.Unmarshal(doc, elem.Interface()); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	ptr.Elem().Set(slice)
+	return nil
+}
@@ -0,0 +1,29 @@
+package mongolib
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPaginator_InvalidSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		var result []person
+		_, err := Query{}.Paginate(size).Find(context.Background(), &result)
+		assert.Error(t, err)
+	}
+}
+
+func TestSetPageTokenKey(t *testing.T) {
+	defer func(key []byte) { pageTokenKey = key }(pageTokenKey)
+
+	for _, key := range [][]byte{nil, {}, []byte("short")} {
+		assert.Error(t, SetPageTokenKey(key))
+	}
+	key := bytes.Repeat([]byte{1}, sha256.Size)
+	assert.NoError(t, SetPageTokenKey(key))
+	key[0] = 2
+	assert.Equal(t, bytes.Repeat([]byte{1}, sha256.Size), pageTokenKey)
+}
@@ -0,0 +1,62 @@
+package mongolib
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Projection selects the fields returned by a query. Inclusions and
+// exclusions cannot be mixed, except for excluding _id.
+type Projection bson.D
+
+func NewProjection() Projection {
+	return Projection{}
+}
+
+func (p Projection) Include(fields ...string) Projection {
+	for _, field := range fields {
+		p = p.with(bson.E{Key: field, Value: 1})
+	}
+	return p
+}
+
+func (p Projection) Exclude(fields ...string) Projection {
+	for _, field := range fields {
+		p = p.with(bson.E{Key: field, Value: 0})
+	}
+	return p
+}
+
+// Compute returns field set to value, usually an Expr. Computed fields are
+// only accepted by the Aggregate.Project stage.
+func (p Projection) Compute(field string, value interface{}) Projection {
+	return p.with(bson.E{Key: field, Value: value})
+}
+
+// Slice returns limit elements of the array field, starting after skip
+// elements. A negative skip counts from the end of the array.
+func (p Projection) Slice(key string, skip, limit int) Projection {
+	return p.with(bson.E{Key: key, Value: bson.D{{Key: "$slice", Value: bson.A{skip, limit}}}})
+}
+
+// ElemMatch returns only the first element of the array field that satisfies
+// filter. As with Filter.ElemMatch, conditions with an empty key apply to the
+// element itself.
+func (p Projection) ElemMatch(key string, filter Filter) Projection {
+	return p.with(bson.E{Key: key, Value: bson.D{{Key: "$elemMatch", Value: filter.elemMatch()}}})
+}
+
+// Positional returns only the first element of the array field matched by the
+// query filter, which must contain a condition on that array.
+func (p Projection) Positional(key string) Projection {
+	return p.with(bson.E{Key: key + ".$", Value: 1})
+}
+
+// TextScore returns the relevance score of a Text search in field.
+func (p Projection) TextScore(field string) Projection {
+	return p.with(bson.E{Key: field, Value: bson.D{{Key: "$meta", Value: "textScore"}}})
+}
+
+// with returns a copy of p with field added, leaving the receiver unchanged.
+func (p Projection) with(field bson.E) Projection {
+	projection := make(Projection, 0, len(p)+1)
+	projection = append(projection, p...)
+	return append(projection, field)
+}
@@ -2,7 +2,10 @@ package mongolib
 
 import (
 	"context"
+	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -12,66 +15,223 @@ const (
 )
 
 type Query struct {
-	coll   *Collection
-	filter bson.A
-	limit  int
-	offset int
-	sort   bson.D
-	update bson.D
+	coll         *Collection
+	filter       Filter
+	limit        int
+	offset       int
+	sort         bson.D
+	projection   Projection
+	update       updateBuilder
+	arrayFilters []interface{}
+	collation    *options.Collation
+
+	upsert          bool
+	returnNew       bool
+	notFoundIfEmpty bool
 }
 
 // Filter
 
+func (q Query) Where(filter Filter) Query {
+	filters := make(Filter, 0, len(q.filter)+len(filter))
+	filters = append(filters, q.filter...)
+	q.filter = append(filters, filter...)
+	return q
+}
+
 func (q Query) Equal(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: value}})
+	q.filter = q.filter.Equal(key, value)
 	return q
 }
 
 func (q Query) Regex(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$regex", value}}}})
+	q.filter = q.filter.Regex(key, value)
+	return q
+}
+
+func (q Query) RegexWithOptions(key, pattern, options string) Query {
+	q.filter = q.filter.RegexWithOptions(key, pattern, options)
+	return q
+}
+
+func (q Query) StartsWith(key, prefix string, opts ...MatchOption) Query {
+	q.filter = q.filter.StartsWith(key, prefix, opts...)
+	return q
+}
+
+func (q Query) EndsWith(key, suffix string, opts ...MatchOption) Query {
+	q.filter = q.filter.EndsWith(key, suffix, opts...)
+	return q
+}
+
+func (q Query) Contains(key, substr string, opts ...MatchOption) Query {
+	q.filter = q.filter.Contains(key, substr, opts...)
+	return q
+}
+
+func (q Query) EqualFold(key, value string) Query {
+	q.filter = q.filter.EqualFold(key, value)
 	return q
 }
 
 func (q Query) NotEqual(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$ne", value}}}})
+	q.filter = q.filter.NotEqual(key, value)
 	return q
 }
 
 func (q Query) GreaterThan(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$gt", value}}}})
+	q.filter = q.filter.GreaterThan(key, value)
 	return q
 }
 
 func (q Query) GreaterThanEqual(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$gte", value}}}})
+	q.filter = q.filter.GreaterThanEqual(key, value)
 	return q
 }
 
 func (q Query) LessThan(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$lt", value}}}})
+	q.filter = q.filter.LessThan(key, value)
 	return q
 }
 
 func (q Query) LessThanEqual(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$lte", value}}}})
+	q.filter = q.filter.LessThanEqual(key, value)
 	return q
 }
 
 func (q Query) In(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$in", value}}}})
+	q.filter = q.filter.In(key, value)
 	return q
 }
 
 func (q Query) NotIn(key string, value interface{}) Query {
-	q.filter = append(q.filter, bson.D{{Key: key, Value: bson.D{{"$nin", value}}}})
+	q.filter = q.filter.NotIn(key, value)
+	return q
+}
+
+func (q Query) Exists(key string, exists bool) Query {
+	q.filter = q.filter.Exists(key, exists)
+	return q
+}
+
+func (q Query) Type(key string, types ...bsontype.Type) Query {
+	q.filter = q.filter.Type(key, types...)
+	return q
+}
+
+func (q Query) IsNull(key string) Query {
+	q.filter = q.filter.IsNull(key)
+	return q
+}
+
+func (q Query) IsMissing(key string) Query {
+	q.filter = q.filter.IsMissing(key)
+	return q
+}
+
+func (q Query) ElemMatch(key string, filter Filter) Query {
+	q.filter = q.filter.ElemMatch(key, filter)
+	return q
+}
+
+func (q Query) All(key string, values ...interface{}) Query {
+	q.filter = q.filter.All(key, values...)
+	return q
+}
+
+func (q Query) Size(key string, size int) Query {
+	q.filter = q.filter.Size(key, size)
+	return q
+}
+
+func (q Query) Text(search string, opts ...TextOptions) Query {
+	q.filter = q.filter.Text(search, opts...)
+	return q
+}
+
+func (q Query) Near(key string, point Point, maxDistance, minDistance float64) Query {
+	q.filter = q.filter.Near(key, point, maxDistance, minDistance)
+	return q
+}
+
+func (q Query) NearSphere(key string, point Point, maxDistance, minDistance float64) Query {
+	q.filter = q.filter.NearSphere(key, point, maxDistance, minDistance)
+	return q
+}
+
+func (q Query) GeoWithin(key string, geometry Geometry) Query {
+	q.filter = q.filter.GeoWithin(key, geometry)
+	return q
+}
+
+func (q Query) GeoWithinBox(key string, bottomLeft, upperRight Point) Query {
+	q.filter = q.filter.GeoWithinBox(key, bottomLeft, upperRight)
+	return q
+}
+
+func (q Query) GeoWithinCenterSphere(key string, center Point, radius float64) Query {
+	q.filter = q.filter.GeoWithinCenterSphere(key, center, radius)
+	return q
+}
+
+func (q Query) GeoIntersects(key string, geometry Geometry) Query {
+	q.filter = q.filter.GeoIntersects(key, geometry)
+	return q
+}
+
+func (q Query) Expr(expr Expr) Query {
+	q.filter = q.filter.Expr(expr)
+	return q
+}
+
+func (q Query) And(filters ...Filter) Query {
+	q.filter = q.filter.And(filters...)
+	return q
+}
+
+func (q Query) Or(filters ...Filter) Query {
+	q.filter = q.filter.Or(filters...)
+	return q
+}
+
+func (q Query) Nor(filters ...Filter) Query {
+	q.filter = q.filter.Nor(filters...)
+	return q
+}
+
+func (q Query) Not(filter Filter) Query {
+	q.filter = q.filter.Not(filter)
 	return q
 }
 
 func (q Query) Sort(key string, order int) Query {
-	q.sort = append(q.sort, bson.E{Key: key, Value: order})
+	q.sort = q.withSort(bson.E{Key: key, Value: order})
 	return q
 }
 
+// SortByTextScore sorts by relevance of a Text search, most relevant first,
+// and returns the score in field.
+func (q Query) SortByTextScore(field string) Query {
+	q.projection = q.projection.TextScore(field)
+	q.sort = q.withSort(bson.E{Key: field, Value: bson.D{{Key: "$meta", Value: "textScore"}}})
+	return q
+}
+
+// Collation sets the language rules used to compare strings, for example
+// &options.Collation{Locale: "en", Strength: 2} to ignore case.
+func (q Query) Collation(collation *options.Collation) Query {
+	q.collation = collation
+	return q
+}
+
+// withSort returns a copy of the sort keys with key added, so that queries
+// derived from the same base do not share them.
+func (q Query) withSort(key bson.E) bson.D {
+	sort := make(bson.D, 0, len(q.sort)+1)
+	sort = append(sort, q.sort...)
+	return append(sort, key)
+}
+
 func (q Query) Limit(limit int) Query {
 	q.limit = limit
 	return q
@@ -82,68 +242,234 @@ func (q Query) Offset(offset int) Query {
 	return q
 }
 
+// NotFoundIfEmpty makes Find report ErrNotFound when no document matched,
+// as FindOne does, instead of decoding an empty slice. Updates and deletes
+// that match no document report ErrNotFound along with their WriteResult.
+func (q Query) NotFoundIfEmpty() Query {
+	q.notFoundIfEmpty = true
+	return q
+}
+
+// Upsert makes writes insert a new document when none matched the filter.
+func (q Query) Upsert() Query {
+	q.upsert = true
+	return q
+}
+
+// ReturnNew makes the find-and-modify operations return the document as it
+// is after the change rather than before it.
+func (q Query) ReturnNew() Query {
+	q.returnNew = true
+	return q
+}
+
+// Projection
+
+func (q Query) Project(projection Projection) Query {
+	fields := make(Projection, 0, len(q.projection)+len(projection))
+	fields = append(fields, q.projection...)
+	q.projection = append(fields, projection...)
+	return q
+}
+
+func (q Query) Select(fields ...string) Query {
+	q.projection = q.projection.Include(fields...)
+	return q
+}
+
+func (q Query) Exclude(fields ...string) Query {
+	q.projection = q.projection.Exclude(fields...)
+	return q
+}
+
+func (q Query) Slice(key string, skip, limit int) Query {
+	q.projection = q.projection.Slice(key, skip, limit)
+	return q
+}
+
+func (q Query) SelectElemMatch(key string, filter Filter) Query {
+	q.projection = q.projection.ElemMatch(key, filter)
+	return q
+}
+
+func (q Query) SelectPositional(key string) Query {
+	q.projection = q.projection.Positional(key)
+	return q
+}
+
 // Update Operation
 
 func (q Query) Set(key string, value interface{}) Query {
-	q.update = append(q.update, bson.E{Key: "$set", Value: bson.D{{Key: key, Value: value}}})
+	q.update = q.update.add("$set", key, value)
+	return q
+}
+
+// SetOnInsert sets key only when an upsert inserts a new document.
+func (q Query) SetOnInsert(key string, value interface{}) Query {
+	q.update = q.update.add("$setOnInsert", key, value)
+	return q
+}
+
+func (q Query) Unset(keys ...string) Query {
+	for _, key := range keys {
+		q.update = q.update.add("$unset", key, "")
+	}
+	return q
+}
+
+// Inc adds value, which may be any integer, float or Decimal128, to key.
+func (q Query) Inc(key string, value interface{}) Query {
+	if !isNumber(value) {
+		q.update = q.update.fail(fmt.Errorf("mongolib: cannot increment %q by non-numeric %T", key, value))
+		return q
+	}
+	q.update = q.update.add("$inc", key, value)
 	return q
 }
 
-func (q Query) Inc(key string, value int) Query {
-	q.update = append(q.update, bson.E{Key: "$inc", Value: bson.D{{Key: key, Value: value}}})
+// Mul multiplies key by value, which may be any integer, float or
+// Decimal128.
+func (q Query) Mul(key string, value interface{}) Query {
+	if !isNumber(value) {
+		q.update = q.update.fail(fmt.Errorf("mongolib: cannot multiply %q by non-numeric %T", key, value))
+		return q
+	}
+	q.update = q.update.add("$mul", key, value)
+	return q
+}
+
+// Min sets key to value if value is less than the current value.
+func (q Query) Min(key string, value interface{}) Query {
+	q.update = q.update.add("$min", key, value)
+	return q
+}
+
+// Max sets key to value if value is greater than the current value.
+func (q Query) Max(key string, value interface{}) Query {
+	q.update = q.update.add("$max", key, value)
+	return q
+}
+
+func (q Query) Rename(from, to string) Query {
+	q.update = q.update.touch("$rename", to).add("$rename", from, to)
+	return q
+}
+
+// CurrentDate sets key to the current date on the server.
+func (q Query) CurrentDate(key string) Query {
+	q.update = q.update.add("$currentDate", key, true)
 	return q
 }
 
 func (q Query) Push(key string, value ...interface{}) Query {
-	q.update = append(q.update, bson.E{Key: "$push", Value: bson.D{{Key: key, Value: bson.D{{Key: "$each", Value: value}}}}})
+	q.update = q.update.add("$push", key, bson.D{{Key: "$each", Value: value}})
+	return q
+}
+
+// PushWith appends values to the array key, applying the position, slice
+// and sort modifiers of opts.
+func (q Query) PushWith(key string, opts *PushOptions, value ...interface{}) Query {
+	q.update = q.update.add("$push", key, opts.modifiers(value))
+	return q
+}
+
+// AddToSet appends the values that the array key does not contain yet.
+func (q Query) AddToSet(key string, value ...interface{}) Query {
+	q.update = q.update.add("$addToSet", key, bson.D{{Key: "$each", Value: value}})
+	return q
+}
+
+func (q Query) PopFirst(key string) Query {
+	q.update = q.update.add("$pop", key, -1)
+	return q
+}
+
+func (q Query) PopLast(key string) Query {
+	q.update = q.update.add("$pop", key, 1)
 	return q
 }
 
 func (q Query) Pull(key string, value ...interface{}) Query {
-	q.update = append(q.update, bson.E{Key: "$pull", Value: bson.D{{Key: key, Value: bson.D{{Key: "$in", Value: value}}}}})
+	q.update = q.update.add("$pull", key, bson.D{{Key: "$in", Value: value}})
+	return q
+}
+
+// PullAll removes every element of the array key equal to one of the values.
+func (q Query) PullAll(key string, value ...interface{}) Query {
+	q.update = q.update.add("$pullAll", key, bson.A(value))
+	return q
+}
+
+// UpdatePipeline computes the update from the stages of pipeline, built
+// with NewPipeline, instead of update operators. Only the AddField, Set,
+// Project, Unset, ReplaceRoot and ReplaceWith stages are accepted, and
+// MongoDB 4.2 is required, for example:
+//
+//	q.UpdatePipeline(NewPipeline().Set("fullName", Concat(Field("first"), " ", Field("last"))))
+func (q Query) UpdatePipeline(pipeline Aggregate) Query {
+	q.update.pipeline = append(mongo.Pipeline{}, pipeline.pipeline...)
+	return q
+}
+
+// ArrayFilter selects the array elements updated through
+// FilteredPositionalPath(array, identifier, ...). The keys of filter are
+// relative to the element, and an empty key matches the element itself, so
+// ArrayFilter("g", NewFilter().GreaterThan("score", 80)) matches elements
+// whose score is above 80.
+func (q Query) ArrayFilter(identifier string, filter Filter) Query {
+	arrayFilters := make([]interface{}, 0, len(q.arrayFilters)+1)
+	arrayFilters = append(arrayFilters, q.arrayFilters...)
+	q.arrayFilters = append(arrayFilters, arrayFilter(identifier, filter))
 	return q
 }
 
 // Execute
 
-func (q Query) Find(ctx context.Context) Result {
-	filter := bson.D{}
-	if len(q.filter) > 0 {
-		filter = bson.D{{Key: "$and", Value: q.filter}}
-	}
+func (q Query) Find(ctx context.Context) *MultipleResult {
+	filter := q.filter.document()
 
 	opt := options.Find().SetSort(q.sort)
+	if len(q.projection) > 0 {
+		opt = opt.SetProjection(q.projection)
+	}
 	if q.limit > 0 {
 		opt = opt.SetLimit(int64(q.limit))
 	}
 	if q.offset > 0 {
 		opt = opt.SetSkip(int64(q.offset))
 	}
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
 
 	cur, err := q.coll.Find(ctx, filter, opt)
 	if err != nil {
 		return &MultipleResult{
 			Cursor: nil,
-			Error:  err,
+			Error:  classify(err),
 		}
 	}
 
 	return &MultipleResult{
-		Cursor: cur,
-		Error:  nil,
+		Cursor:          cur,
+		Error:           nil,
+		NotFoundIfEmpty: q.notFoundIfEmpty,
 	}
 }
 
 func (q Query) FindOne(ctx context.Context) Result {
-	filter := bson.D{}
-	if len(q.filter) > 0 {
-		filter = bson.D{{Key: "$and", Value: q.filter}}
-	}
+	filter := q.filter.document()
 
 	opt := options.FindOne().SetSort(q.sort)
+	if len(q.projection) > 0 {
+		opt = opt.SetProjection(q.projection)
+	}
 	if q.offset > 0 {
 		opt = opt.SetSkip(int64(q.offset))
 	}
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
 
 	result := q.coll.FindOne(ctx, filter, opt)
 	return &SingleResult{
@@ -152,10 +478,7 @@ func (q Query) FindOne(ctx context.Context) Result {
 }
 
 func (q Query) Count(ctx context.Context) (int, error) {
-	filter := bson.D{}
-	if len(q.filter) > 0 {
-		filter = bson.D{{Key: "$and", Value: q.filter}}
-	}
+	filter := q.filter.document()
 
 	opt := options.Count()
 	if q.offset > 0 {
@@ -164,56 +487,238 @@ func (q Query) Count(ctx context.Context) (int, error) {
 	if q.limit > 0 {
 		opt = opt.SetLimit(int64(q.limit))
 	}
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
 
 	count, err := q.coll.CountDocuments(ctx, filter, opt)
 	if err != nil {
-		return 0, err
+		return 0, classify(err)
 	}
 
 	return int(count), nil
 }
 
-func (q Query) Save(ctx context.Context, data interface{}) error {
-	filter := bson.D{}
-	if len(q.filter) > 0 {
-		filter = bson.D{{Key: "$and", Value: q.filter}}
+// Distinct decodes the distinct values of field among the matching documents
+// into v, which must be a pointer to a slice.
+func (q Query) Distinct(ctx context.Context, field string, v interface{}) error {
+	filter := q.filter.document()
+
+	opt := options.Distinct()
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
 	}
 
-	opt := options.Update().SetUpsert(true)
+	values, err := q.coll.Collection.Distinct(ctx, field, filter, opt)
+	if err != nil {
+		return classify(err)
+	}
 
-	update := bson.D{{"$set", data}}
-	if _, err := q.coll.UpdateMany(ctx, filter, update, opt); err != nil {
+	raw, err := bson.Marshal(bson.D{{Key: "values", Value: values}})
+	if err != nil {
 		return err
 	}
+	return bson.Raw(raw).Lookup("values").Unmarshal(v)
+}
+
+// Save sets the fields of data on every matching document. Like the other
+// writes it only inserts a document when Upsert is set.
+func (q Query) Save(ctx context.Context, data interface{}) (WriteResult, error) {
+	filter := q.filter.document()
+
+	update := bson.D{{"$set", data}}
+	res, err := q.coll.UpdateMany(ctx, filter, update, q.updateOptions())
+	if err != nil {
+		return WriteResult{}, classify(err)
+	}
 
-	return nil
+	return q.updateResult(res)
 }
 
-func (q Query) Update(ctx context.Context) error {
-	filter := bson.D{}
-	if len(q.filter) > 0 {
-		filter = bson.D{{Key: "$and", Value: q.filter}}
+// Update is UpdateMany.
+func (q Query) Update(ctx context.Context) (WriteResult, error) {
+	return q.UpdateMany(ctx)
+}
+
+// UpdateOne applies the update operators to the first matching document.
+func (q Query) UpdateOne(ctx context.Context) (WriteResult, error) {
+	filter := q.filter.document()
+	update, err := q.update.document()
+	if err != nil {
+		return WriteResult{}, err
 	}
 
-	opt := options.Update().SetUpsert(true)
+	res, err := q.coll.Collection.UpdateOne(ctx, filter, update, q.updateOptions())
+	if err != nil {
+		return WriteResult{}, classify(err)
+	}
 
-	if _, err := q.coll.UpdateMany(ctx, filter, q.update, opt); err != nil {
-		return err
+	return q.updateResult(res)
+}
+
+// UpdateMany applies the update operators to every matching document.
+func (q Query) UpdateMany(ctx context.Context) (WriteResult, error) {
+	filter := q.filter.document()
+	update, err := q.update.document()
+	if err != nil {
+		return WriteResult{}, err
+	}
+
+	res, err := q.coll.Collection.UpdateMany(ctx, filter, update, q.updateOptions())
+	if err != nil {
+		return WriteResult{}, classify(err)
 	}
 
-	return nil
+	return q.updateResult(res)
 }
 
-func (q Query) Delete(ctx context.Context) error {
-	filter := bson.D{}
-	if len(q.filter) > 0 {
-		filter = bson.D{{Key: "$and", Value: q.filter}}
+// Delete is DeleteMany.
+func (q Query) Delete(ctx context.Context) (WriteResult, error) {
+	return q.DeleteMany(ctx)
+}
+
+// DeleteOne deletes the first matching document.
+func (q Query) DeleteOne(ctx context.Context) (WriteResult, error) {
+	filter := q.filter.document()
+
+	res, err := q.coll.Collection.DeleteOne(ctx, filter, q.deleteOptions())
+	if err != nil {
+		return WriteResult{}, classify(err)
 	}
 
-	_, err := q.coll.DeleteMany(ctx, filter)
+	return q.deleteResult(res)
+}
+
+// DeleteMany deletes every matching document.
+func (q Query) DeleteMany(ctx context.Context) (WriteResult, error) {
+	filter := q.filter.document()
+
+	res, err := q.coll.Collection.DeleteMany(ctx, filter, q.deleteOptions())
 	if err != nil {
-		return err
+		return WriteResult{}, classify(err)
 	}
 
-	return nil
-}
\ No newline at end of file
+	return q.deleteResult(res)
+}
+
+func (q Query) updateResult(res *mongo.UpdateResult) (WriteResult, error) {
+	result := WriteResult{
+		Matched:    res.MatchedCount,
+		Modified:   res.ModifiedCount,
+		Upserted:   res.UpsertedCount,
+		UpsertedID: res.UpsertedID,
+	}
+	if q.notFoundIfEmpty && result.Matched == 0 && result.Upserted == 0 {
+		return result, ErrNotFound
+	}
+	return result, nil
+}
+
+func (q Query) deleteResult(res *mongo.DeleteResult) (WriteResult, error) {
+	result := WriteResult{
+		Deleted: res.DeletedCount,
+	}
+	if q.notFoundIfEmpty && result.Deleted == 0 {
+		return result, ErrNotFound
+	}
+	return result, nil
+}
+
+func (q Query) updateOptions() *options.UpdateOptions {
+	opt := options.Update().SetUpsert(q.upsert)
+	if len(q.arrayFilters) > 0 {
+		opt = opt.SetArrayFilters(options.ArrayFilters{Filters: q.arrayFilters})
+	}
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
+	return opt
+}
+
+func (q Query) deleteOptions() *options.DeleteOptions {
+	opt := options.Delete()
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
+	return opt
+}
+
+// FindOneAndUpdate atomically applies the update operators to the first
+// matching document in sort order and returns it.
+func (q Query) FindOneAndUpdate(ctx context.Context) Result {
+	filter := q.filter.document()
+
+	opt := options.FindOneAndUpdate().
+		SetSort(q.sort).
+		SetUpsert(q.upsert).
+		SetReturnDocument(q.returnDocument())
+	if len(q.projection) > 0 {
+		opt = opt.SetProjection(q.projection)
+	}
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
+	if len(q.arrayFilters) > 0 {
+		opt = opt.SetArrayFilters(options.ArrayFilters{Filters: q.arrayFilters})
+	}
+
+	update, err := q.update.document()
+	if err != nil {
+		return &SingleResult{
+			Error: err,
+		}
+	}
+
+	result := q.coll.Collection.FindOneAndUpdate(ctx, filter, update, opt)
+	return &SingleResult{
+		SingleResult: result,
+	}
+}
+
+// FindOneAndReplace atomically replaces the first matching document in sort
+// order with replacement and returns it.
+func (q Query) FindOneAndReplace(ctx context.Context, replacement interface{}) Result {
+	filter := q.filter.document()
+
+	opt := options.FindOneAndReplace().
+		SetSort(q.sort).
+		SetUpsert(q.upsert).
+		SetReturnDocument(q.returnDocument())
+	if len(q.projection) > 0 {
+		opt = opt.SetProjection(q.projection)
+	}
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
+
+	result := q.coll.Collection.FindOneAndReplace(ctx, filter, replacement, opt)
+	return &SingleResult{
+		SingleResult: result,
+	}
+}
+
+// FindOneAndDelete atomically deletes the first matching document in sort
+// order and returns it.
+func (q Query) FindOneAndDelete(ctx context.Context) Result {
+	filter := q.filter.document()
+
+	opt := options.FindOneAndDelete().SetSort(q.sort)
+	if len(q.projection) > 0 {
+		opt = opt.SetProjection(q.projection)
+	}
+	if q.collation != nil {
+		opt = opt.SetCollation(q.collation)
+	}
+
+	result := q.coll.Collection.FindOneAndDelete(ctx, filter, opt)
+	return &SingleResult{
+		SingleResult: result,
+	}
+}
+
+func (q Query) returnDocument() options.ReturnDocument {
+	if q.returnNew {
+		return options.After
+	}
+	return options.Before
+}
@@ -2,8 +2,11 @@ package mongolib
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/strikesecurity/strikememongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,6 +21,7 @@ type person struct {
 	Car   car                `bson:"car"`
 	Score []int              `bson:"score"`
 	Alias []string           `bson:"alias"`
+	Cars  []car              `bson:"cars"`
 }
 
 type car struct {
@@ -78,18 +82,18 @@ func TestQuery_Set(t *testing.T) {
 		{
 			name: "success: update set attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Set("name", after.Name).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Name, result.Name)
 			},
@@ -98,18 +102,18 @@ func TestQuery_Set(t *testing.T) {
 		{
 			name: "success: update set nested attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Set("car.color", after.Car.Color).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Car.Color, result.Car.Color)
 			},
@@ -118,11 +122,11 @@ func TestQuery_Set(t *testing.T) {
 		{
 			name: "success: update set multiple attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Set("name", after.Name).
 					Set("car.color", after.Car.Color).
 					Update(ctx)
@@ -130,7 +134,7 @@ func TestQuery_Set(t *testing.T) {
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Name, result.Name)
 				assert.Equal(t, after.Car.Color, result.Car.Color)
@@ -188,18 +192,18 @@ func TestQuery_Push(t *testing.T) {
 		{
 			name: "success: update push array attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Push("score", newScore).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Score, result.Score)
 			},
@@ -208,18 +212,18 @@ func TestQuery_Push(t *testing.T) {
 		{
 			name: "success: update push array attribute multiple value",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Push("score", newScore, 1).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, append(after.Score, 1), result.Score)
 			},
@@ -228,11 +232,11 @@ func TestQuery_Push(t *testing.T) {
 		{
 			name: "success: update push array multiple attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Push("score", newScore).
 					Push("alias", "Batman").
 					Update(ctx)
@@ -240,7 +244,7 @@ func TestQuery_Push(t *testing.T) {
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Score, result.Score)
 			},
@@ -297,18 +301,18 @@ func TestQuery_Pull(t *testing.T) {
 		{
 			name: "success: update pull array attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Pull("score", deletedScore).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Score, result.Score)
 			},
@@ -317,18 +321,18 @@ func TestQuery_Pull(t *testing.T) {
 		{
 			name: "success: update pull array attribute multiple value",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Pull("score", deletedScore, 13, 93).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, []int{80}, result.Score)
 			},
@@ -337,11 +341,11 @@ func TestQuery_Pull(t *testing.T) {
 		{
 			name: "success: update pull array multiple attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Pull("score", deletedScore).
 					Pull("alias", "Joker").
 					Update(ctx)
@@ -349,7 +353,7 @@ func TestQuery_Pull(t *testing.T) {
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Score, result.Score)
 				assert.Equal(t, []string{"Batman"}, result.Alias)
@@ -412,18 +416,18 @@ func TestQuery_Inc(t *testing.T) {
 		{
 			name: "success: update increase attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Inc("age", 1).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Age, result.Age)
 			},
@@ -432,18 +436,18 @@ func TestQuery_Inc(t *testing.T) {
 		{
 			name: "success: update decrease attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Inc("age", -2).
 					Update(ctx)
 				assert.NoError(t, err)
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, before.Age-2, result.Age)
 			},
@@ -452,11 +456,11 @@ func TestQuery_Inc(t *testing.T) {
 		{
 			name: "success: update increase multiple attribute",
 			prepare: func() {
-				err := coll.Save(ctx, before.ID, before)
+				_, err := coll.Save(ctx, before.ID, before)
 				assert.NoError(t, err)
 			},
 			action: func() {
-				err := coll.Query().
+				_, err := coll.Query().
 					Inc("age", 1).
 					Inc("car.speed", 5).
 					Update(ctx)
@@ -464,7 +468,7 @@ func TestQuery_Inc(t *testing.T) {
 			},
 			assert: func() {
 				var result person
-				err := coll.Query().FindOne(ctx).Consume(&result)
+				err := coll.Query().FindOne(ctx).Consume(ctx, &result)
 				assert.NoError(t, err)
 				assert.Equal(t, after.Age, result.Age)
 				assert.Equal(t, after.Car.Speed, result.Car.Speed)
@@ -487,4 +491,1492 @@ func TestQuery_Inc(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestQuery_Or(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		trevor = person{
+			ID:   NewObjectID(),
+			Name: "Trevor",
+			Age:  27,
+			Car: car{
+				Color: "red",
+			},
+		}
+		ali = person{
+			ID:   NewObjectID(),
+			Name: "Ali",
+			Age:  31,
+			Car: car{
+				Color: "blue",
+			},
+		}
+		budi = person{
+			ID:   NewObjectID(),
+			Name: "Budi",
+			Age:  19,
+			Car: car{
+				Color: "red",
+			},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find with or",
+			prepare: func() {
+				for _, p := range []person{trevor, ali, budi} {
+					_, err := coll.Save(ctx, p.ID, p)
+					assert.NoError(t, err)
+				}
+			},
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Or(NewFilter().Equal("name", ali.Name), NewFilter().Equal("name", budi.Name)).
+					Sort("age", Ascending).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{budi, ali}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find with nested or and not",
+			prepare: func() {
+				for _, p := range []person{trevor, ali, budi} {
+					_, err := coll.Save(ctx, p.ID, p)
+					assert.NoError(t, err)
+				}
+			},
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Or(
+						NewFilter().Equal("car.color", "blue"),
+						NewFilter().Equal("car.color", "red").Not(NewFilter().LessThan("age", 20)),
+					).
+					Sort("age", Ascending).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{trevor, ali}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find with nor",
+			prepare: func() {
+				for _, p := range []person{trevor, ali, budi} {
+					_, err := coll.Save(ctx, p.ID, p)
+					assert.NoError(t, err)
+				}
+			},
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Nor(NewFilter().Equal("name", ali.Name), NewFilter().Equal("name", budi.Name)).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{trevor}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Exists(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		named = bson.D{{Key: "_id", Value: NewObjectID()}, {Key: "name", Value: "Trevor"}}
+		null = bson.D{{Key: "_id", Value: NewObjectID()}, {Key: "name", Value: nil}}
+		missing = bson.D{{Key: "_id", Value: NewObjectID()}}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find existing field includes null",
+			assert: func() {
+				count, err := coll.Query().Exists("name", true).Count(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, 2, count)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find null field excludes missing",
+			assert: func() {
+				var result []bson.D
+				err := coll.Query().IsNull("name").Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []bson.D{null}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find missing field excludes null",
+			assert: func() {
+				var result []bson.D
+				err := coll.Query().IsMissing("name").Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []bson.D{missing}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find by type",
+			assert: func() {
+				var result []bson.D
+				err := coll.Query().Type("name", bsontype.String).Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []bson.D{named}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.InsertMany(ctx, []interface{}{named, null, missing})
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_ElemMatch(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		trevor = person{
+			ID:    NewObjectID(),
+			Name:  "Trevor",
+			Score: []int{93, 80, 13},
+			Alias: []string{"Joker", "Batman"},
+			Cars: []car{
+				{Color: "red", Speed: 10},
+				{Color: "blue", Speed: 50},
+			},
+		}
+		ali = person{
+			ID:    NewObjectID(),
+			Name:  "Ali",
+			Score: []int{80},
+			Alias: []string{"Batman"},
+			Cars: []car{
+				{Color: "red", Speed: 50},
+			},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find with elem match on array of subdocument",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					ElemMatch("cars", NewFilter().Equal("color", "red").GreaterThan("speed", 20)).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{ali}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find with elem match on array of scalar",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					ElemMatch("score", NewFilter().GreaterThan("", 80).LessThan("", 95)).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{trevor}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find with all values",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					All("alias", "Batman", "Joker").
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{trevor}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find with array size",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Size("score", 1).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{ali}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range []person{trevor, ali} {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Text(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		trevor = person{
+			ID:    NewObjectID(),
+			Name:  "Trevor Philips",
+			Alias: []string{"Joker"},
+		}
+		ali = person{
+			ID:    NewObjectID(),
+			Name:  "Ali",
+			Alias: []string{"Trevor", "Trevor Junior"},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find by text sorted by score",
+			assert: func() {
+				var result []struct {
+					person `bson:",inline"`
+					Score  float64 `bson:"score"`
+				}
+				err := coll.Query().
+					Text("trevor").
+					SortByTextScore("score").
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, 2)
+				assert.True(t, result[0].Score >= result[1].Score)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find by case sensitive text",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Text("trevor", TextOptions{CaseSensitive: true}).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Empty(t, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.CreateTextIndex(ctx, "name", "alias")
+			assert.NoError(t, err)
+			for _, p := range []person{trevor, ali} {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+type place struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Name     string             `bson:"name"`
+	Location Point              `bson:"location"`
+}
+
+func TestQuery_Geo(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx     = context.Background()
+		coll    = db.Coll(collName)
+		monas   = place{ID: NewObjectID(), Name: "Monas", Location: Point{Longitude: 106.8272, Latitude: -6.1754}}
+		kota    = place{ID: NewObjectID(), Name: "Kota Tua", Location: Point{Longitude: 106.8133, Latitude: -6.1352}}
+		bogor   = place{ID: NewObjectID(), Name: "Bogor", Location: Point{Longitude: 106.7972, Latitude: -6.5950}}
+		jakarta = Polygon{{
+			{Longitude: 106.7, Latitude: -6.3},
+			{Longitude: 107.0, Latitude: -6.3},
+			{Longitude: 107.0, Latitude: -6.0},
+			{Longitude: 106.7, Latitude: -6.0},
+			{Longitude: 106.7, Latitude: -6.3},
+		}}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find near sorted by distance",
+			assert: func() {
+				var result []place
+				err := coll.Query().
+					Near("location", monas.Location, 10000, 0).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []place{monas, kota}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find near with min distance and other filter",
+			assert: func() {
+				var result []place
+				err := coll.Query().
+					Near("location", monas.Location, 0, 1000).
+					NotEqual("name", "Kota Tua").
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []place{bogor}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find within polygon",
+			assert: func() {
+				var result []place
+				err := coll.Query().
+					GeoWithin("location", jakarta).
+					Sort("name", Ascending).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []place{kota, monas}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find within center sphere",
+			assert: func() {
+				var result []place
+				err := coll.Query().
+					GeoWithinCenterSphere("location", bogor.Location, 1000/6378100.0).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []place{bogor}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.Create2dsphereIndex(ctx, "location")
+			assert.NoError(t, err)
+			for _, p := range []place{monas, kota, bogor} {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Expr(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		trevor = person{
+			ID:   NewObjectID(),
+			Name: "Trevor",
+			Age:  27,
+			Car: car{
+				Speed: 30,
+			},
+		}
+		ali = person{
+			ID:   NewObjectID(),
+			Name: "Ali",
+			Age:  31,
+			Car: car{
+				Speed: 20,
+			},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find comparing two fields",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Expr(Gt(Field("car.speed"), Field("age"))).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{trevor}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find comparing computed field",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Expr(Lt(Add(Field("car.speed"), 10), Field("age"))).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{ali}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range []person{trevor, ali} {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_StartsWith(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		trevor = person{
+			ID:   NewObjectID(),
+			Name: "Trevor (T.) Philips",
+		}
+		ali = person{
+			ID:   NewObjectID(),
+			Name: "TrevorT Ali",
+		}
+		newline = person{
+			ID:   NewObjectID(),
+			Name: "TrevorT Ali\n",
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find starts with escaped prefix",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					StartsWith("name", "Trevor (T.)").
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{trevor}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find ends with ignoring case",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					EndsWith("name", "ali", IgnoreCase).
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{ali}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find contains escaped substring",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Contains("name", "T.").
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{trevor}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find equal fold",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					EqualFold("name", "trevort ali").
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{ali}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range []person{trevor, ali, newline} {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Select(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		trevor = person{
+			ID:    NewObjectID(),
+			Name:  "Trevor",
+			Age:   27,
+			Score: []int{93, 80, 13},
+			Car: car{
+				Color: "red",
+				Speed: 10,
+			},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: find selected fields",
+			assert: func() {
+				var result []person
+				err := coll.Query().
+					Select("name", "car.color").
+					Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []person{{ID: trevor.ID, Name: trevor.Name, Car: car{Color: trevor.Car.Color}}}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find one excluding fields and slicing array",
+			assert: func() {
+				var result person
+				err := coll.Query().
+					Exclude("age", "car").
+					Slice("score", 1, 1).
+					FindOne(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, person{ID: trevor.ID, Name: trevor.Name, Score: []int{80}}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find selecting matching scalar element",
+			assert: func() {
+				var result person
+				err := coll.Query().
+					Select("name").
+					SelectElemMatch("score", NewFilter().GreaterThan("", 50).LessThan("", 90)).
+					FindOne(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, person{ID: trevor.ID, Name: trevor.Name, Score: []int{80}}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: find by id with projection",
+			assert: func() {
+				var result person
+				err := coll.FindByID(ctx, trevor.ID, NewProjection().Include("age")).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, person{ID: trevor.ID, Age: trevor.Age}, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.Save(ctx, trevor.ID, trevor)
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Paginate(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "A", Age: 40},
+			{ID: NewObjectID(), Name: "B", Age: 30},
+			{ID: NewObjectID(), Name: "C", Age: 30},
+			{ID: NewObjectID(), Name: "D", Age: 20},
+			{ID: NewObjectID(), Name: "E", Age: 10},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: paginate forward and backward",
+			assert: func() {
+				query := coll.Query().Sort("age", Descending)
+
+				var first []person
+				tokens, err := query.Paginate(2).Find(ctx, &first)
+				assert.NoError(t, err)
+				assert.Equal(t, people[:2], first)
+				assert.Empty(t, tokens.Previous)
+
+				var second []person
+				tokens, err = query.Paginate(2).After(tokens.Next).Find(ctx, &second)
+				assert.NoError(t, err)
+				assert.Equal(t, people[2:4], second)
+
+				var last []person
+				lastTokens, err := query.Paginate(2).After(tokens.Next).Find(ctx, &last)
+				assert.NoError(t, err)
+				assert.Equal(t, people[4:], last)
+				assert.Empty(t, lastTokens.Next)
+
+				var back []person
+				tokens, err = query.Paginate(2).Before(tokens.Previous).Find(ctx, &back)
+				assert.NoError(t, err)
+				assert.Equal(t, people[:2], back)
+				assert.Empty(t, tokens.Previous)
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed: tampered token",
+			assert: func() {
+				var result []person
+				tokens, err := coll.Query().Paginate(2).Find(ctx, &result)
+				assert.NoError(t, err)
+
+				_, err = coll.Query().Paginate(2).After("x"+tokens.Next).Find(ctx, &result)
+				assert.Equal(t, ErrInvalidPageToken, err)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Page(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "A", Age: 10},
+			{ID: NewObjectID(), Name: "B", Age: 20},
+			{ID: NewObjectID(), Name: "C", Age: 30},
+			{ID: NewObjectID(), Name: "D", Age: 40},
+			{ID: NewObjectID(), Name: "E", Age: 50},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: get middle page",
+			assert: func() {
+				page, err := coll.Query().Sort("age", Ascending).Page(ctx, 2, 2)
+				assert.NoError(t, err)
+				assert.Equal(t, 5, page.Total)
+				assert.Equal(t, 3, page.Pages)
+				assert.True(t, page.HasNext)
+				assert.True(t, page.HasPrevious)
+
+				var result []person
+				err = page.Decode(&result)
+				assert.NoError(t, err)
+				assert.Equal(t, people[2:4], result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: get filtered last page",
+			assert: func() {
+				page, err := coll.Query().GreaterThan("age", 10).Sort("age", Ascending).Page(ctx, 2, 3)
+				assert.NoError(t, err)
+				assert.Equal(t, 4, page.Total)
+				assert.Equal(t, 2, page.Pages)
+				assert.False(t, page.HasNext)
+
+				var result []person
+				err = page.Decode(&result)
+				assert.NoError(t, err)
+				assert.Equal(t, people[4:], result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Each(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "A", Age: 10},
+			{ID: NewObjectID(), Name: "B", Age: 20},
+			{ID: NewObjectID(), Name: "C", Age: 30},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: stream every document",
+			assert: func() {
+				var result []person
+				err := coll.Query().Sort("age", Ascending).Find(ctx).Each(ctx, func(decode func(v interface{}) error) error {
+					var p person
+					if err := decode(&p); err != nil {
+						return err
+					}
+					result = append(result, p)
+					return nil
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, people, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: iterate with next",
+			assert: func() {
+				res := coll.Query().Sort("age", Descending).Find(ctx)
+				defer res.Close(ctx)
+
+				var result []person
+				for res.Next(ctx) {
+					var p person
+					assert.NoError(t, res.Decode(&p))
+					result = append(result, p)
+				}
+				assert.NoError(t, res.Err())
+				assert.Equal(t, []person{people[2], people[1], people[0]}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed: stop on callback error",
+			assert: func() {
+				stop := errors.New("stop")
+				calls := 0
+				err := coll.Query().Find(ctx).Each(ctx, func(decode func(v interface{}) error) error {
+					calls++
+					return stop
+				})
+				assert.Equal(t, stop, err)
+				assert.Equal(t, 1, calls)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Distinct(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "Trevor", Age: 27, Car: car{Color: "red"}},
+			{ID: NewObjectID(), Name: "Ali", Age: 31, Car: car{Color: "Red"}},
+			{ID: NewObjectID(), Name: "Budi", Age: 19, Car: car{Color: "blue"}},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: distinct of filtered documents",
+			assert: func() {
+				var result []string
+				err := coll.Query().GreaterThan("age", 20).Distinct(ctx, "car.color", &result)
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, []string{"red", "Red"}, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: distinct ignoring case",
+			assert: func() {
+				var result []string
+				err := coll.Query().
+					Collation(&options.Collation{Locale: "en", Strength: 2}).
+					Distinct(ctx, "car.color", &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, 2)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_FindOneAndUpdate(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		trevor = person{
+			ID:   NewObjectID(),
+			Name: "Trevor",
+			Age:  27,
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: return document before update",
+			action: func() {
+				var result person
+				err := coll.Query().
+					Equal("_id", trevor.ID).
+					Inc("age", 1).
+					FindOneAndUpdate(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, trevor.Age, result.Age)
+			},
+			assert: func() {
+				var result person
+				err := coll.FindByID(ctx, trevor.ID).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, trevor.Age+1, result.Age)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: return document after update",
+			action: func() {
+				var result person
+				err := coll.Query().
+					Equal("_id", trevor.ID).
+					Inc("age", 1).
+					ReturnNew().
+					FindOneAndUpdate(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, trevor.Age+1, result.Age)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: upsert missing document",
+			action: func() {
+				var result person
+				err := coll.Query().
+					Equal("name", "Ali").
+					Set("age", 31).
+					Upsert().
+					ReturnNew().
+					FindOneAndUpdate(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, "Ali", result.Name)
+				assert.Equal(t, 31, result.Age)
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed: no matching document without upsert",
+			action: func() {
+				var result person
+				err := coll.Query().
+					Equal("name", "Ali").
+					Set("age", 31).
+					FindOneAndUpdate(ctx).Consume(ctx, &result)
+				assert.Equal(t, ErrNotFound, err)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success: replace document",
+			action: func() {
+				replacement := person{ID: trevor.ID, Name: "Ali", Age: 31}
+				var result person
+				err := coll.Query().
+					Equal("_id", trevor.ID).
+					ReturnNew().
+					FindOneAndReplace(ctx, replacement).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, replacement, result)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: delete document",
+			action: func() {
+				var result person
+				err := coll.Query().
+					Equal("_id", trevor.ID).
+					FindOneAndDelete(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, trevor, result)
+			},
+			assert: func() {
+				count, err := coll.Query().Count(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, 0, count)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.Save(ctx, trevor.ID, trevor)
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_Upsert(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "Trevor", Age: 27},
+			{ID: NewObjectID(), Name: "Ali", Age: 27},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: update without upsert does not insert",
+			action: func() {
+				_, err := coll.Query().Equal("name", "Budi").Set("age", 19).Update(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				count, err := coll.Query().Count(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, len(people), count)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: update with upsert inserts",
+			action: func() {
+				_, err := coll.Query().Equal("name", "Budi").Set("age", 19).Upsert().Update(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				var result person
+				err := coll.Query().Equal("name", "Budi").FindOne(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, 19, result.Age)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: update one document",
+			action: func() {
+				_, err := coll.Query().Equal("age", 27).Inc("age", 1).UpdateOne(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				count, err := coll.Query().Equal("age", 28).Count(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, count)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: delete one document",
+			action: func() {
+				_, err := coll.Query().Equal("age", 27).DeleteOne(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				count, err := coll.Query().Count(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, len(people)-1, count)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_WriteResult(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		people = []person{
+			{ID: NewObjectID(), Name: "Trevor", Age: 27},
+			{ID: NewObjectID(), Name: "Ali", Age: 27},
+			{ID: NewObjectID(), Name: "Budi", Age: 19},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: update counts matched and modified",
+			action: func() {
+				res, err := coll.Query().Equal("age", 27).Set("name", "Trevor").Update(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, WriteResult{Matched: 2, Modified: 1}, res)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: upsert returns upserted id",
+			action: func() {
+				res, err := coll.Query().Equal("name", "Dewi").Set("age", 22).Upsert().UpdateOne(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), res.Upserted)
+				assert.NotNil(t, res.UpsertedID)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: delete counts deleted",
+			action: func() {
+				res, err := coll.Query().Equal("age", 27).Delete(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, WriteResult{Deleted: 2}, res)
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed: update matching nothing with not found",
+			action: func() {
+				res, err := coll.Query().Equal("name", "Dewi").Set("age", 22).NotFoundIfEmpty().UpdateOne(ctx)
+				assert.Equal(t, ErrNotFound, err)
+				assert.Equal(t, WriteResult{}, res)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed: delete matching nothing with not found",
+			action: func() {
+				_, err := coll.Query().Equal("name", "Dewi").NotFoundIfEmpty().DeleteOne(ctx)
+				assert.Equal(t, ErrNotFound, err)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			for _, p := range people {
+				_, err := coll.Save(ctx, p.ID, p)
+				assert.NoError(t, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
+
+func TestQuery_ArrayFilter(t *testing.T) {
+	const collName = "coll"
+	db := initTest(t)
+	var (
+		ctx = context.Background()
+		coll = db.Coll(collName)
+		before = person{
+			ID:    NewObjectID(),
+			Name:  "Trevor",
+			Score: []int{93, 80, 13},
+			Cars: []car{
+				{Color: "red", Speed: 10},
+				{Color: "blue", Speed: 20},
+				{Color: "red", Speed: 30},
+			},
+		}
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: update first matched element",
+			action: func() {
+				_, err := coll.Query().
+					Equal("cars.color", "red").
+					Set(PositionalPath("cars", "color"), "green").
+					Update(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				var result person
+				err := coll.FindByID(ctx, before.ID).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"green", "blue", "red"}, []string{result.Cars[0].Color, result.Cars[1].Color, result.Cars[2].Color})
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: update every element",
+			action: func() {
+				_, err := coll.Query().
+					Inc(AllPositionalPath("score"), 1).
+					Update(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				var result person
+				err := coll.FindByID(ctx, before.ID).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []int{94, 81, 14}, result.Score)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: update filtered elements",
+			action: func() {
+				_, err := coll.Query().
+					Inc(FilteredPositionalPath("cars", "c", "speed"), 5).
+					ArrayFilter("c", NewFilter().Equal("color", "red")).
+					UpdateOne(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				var result person
+				err := coll.FindByID(ctx, before.ID).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []int{15, 20, 35}, []int{result.Cars[0].Speed, result.Cars[1].Speed, result.Cars[2].Speed})
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: update filtered values",
+			action: func() {
+				_, err := coll.Query().
+					Set(FilteredPositionalPath("score", "s"), 100).
+					ArrayFilter("s", NewFilter().GreaterThan("", 50)).
+					UpdateOne(ctx)
+				assert.NoError(t, err)
+			},
+			assert: func() {
+				var result person
+				err := coll.FindByID(ctx, before.ID).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Equal(t, []int{100, 100, 13}, result.Score)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			_, err = coll.Save(ctx, before.ID, before)
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
@@ -4,43 +4,123 @@ import (
 	"context"
 	"errors"
 	"go.mongodb.org/mongo-driver/mongo"
+	"reflect"
 )
 
 var (
 	ErrNotFound = errors.New("result not found")
 )
 
+// WriteResult reports the outcome of a write.
+type WriteResult struct {
+	Matched    int64
+	Modified   int64
+	Upserted   int64
+	UpsertedID interface{}
+	Deleted    int64
+}
+
+// Result decodes the documents returned by a read. A SingleResult returns
+// ErrNotFound when no document matched. A MultipleResult decodes an empty
+// slice instead, unless NotFoundIfEmpty is set. Any cursor is closed by the
+// time Consume returns.
 type Result interface {
-	Consume(v interface{}) error
+	Consume(ctx context.Context, v interface{}) error
 }
 
 type SingleResult struct {
 	*mongo.SingleResult
+	Error error
 }
 
-func (r *SingleResult) Consume(v interface{}) error {
+func (r *SingleResult) Consume(ctx context.Context, v interface{}) error {
+	if r.Error != nil {
+		return classify(r.Error)
+	}
 	err := r.Decode(v)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return ErrNotFound
 		}
-		return err
+		return classify(err)
 	}
 	return nil
 }
 
 type MultipleResult struct {
 	*mongo.Cursor
-	Error error
+	Error           error
+	NotFoundIfEmpty bool
 }
 
-func (r *MultipleResult) Consume(v interface{}) error {
+func (r *MultipleResult) Consume(ctx context.Context, v interface{}) error {
+	defer r.Close(ctx)
 	if r.Error != nil {
-		return r.Error
+		return classify(r.Error)
 	}
-	err := r.All(context.TODO(), v)
+	err := r.All(ctx, v)
 	if err != nil {
-		return err
+		return classify(err)
+	}
+	if r.NotFoundIfEmpty && isEmptySlice(v) {
+		return ErrNotFound
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Next advances to the next document, returning false when the results are
+// exhausted or an error occurred. Check Err afterwards.
+func (r *MultipleResult) Next(ctx context.Context) bool {
+	if r.Error != nil || r.Cursor == nil {
+		return false
+	}
+	return r.Cursor.Next(ctx)
+}
+
+// Decode decodes the current document into v.
+func (r *MultipleResult) Decode(v interface{}) error {
+	if r.Error != nil {
+		return classify(r.Error)
+	}
+	return r.Cursor.Decode(v)
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (r *MultipleResult) Err() error {
+	if r.Error != nil {
+		return classify(r.Error)
+	}
+	if r.Cursor == nil {
+		return nil
+	}
+	return classify(r.Cursor.Err())
+}
+
+// Close releases the server-side cursor. It is safe to call more than once.
+func (r *MultipleResult) Close(ctx context.Context) error {
+	if r.Cursor == nil {
+		return nil
+	}
+	return classify(r.Cursor.Close(ctx))
+}
+
+// Each calls fn for every document, one at a time, so that memory use does
+// not grow with the size of the result. Iteration stops at the first error
+// returned by fn, and the cursor is closed before Each returns.
+func (r *MultipleResult) Each(ctx context.Context, fn func(decode func(v interface{}) error) error) error {
+	defer r.Close(ctx)
+	for r.Next(ctx) {
+		if err := fn(r.Decode); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
+
+func isEmptySlice(v interface{}) bool {
+	slice := reflect.ValueOf(v).Elem()
+	if slice.Kind() == reflect.Interface {
+		slice = slice.Elem()
+	}
+	return slice.Len() == 0
+}
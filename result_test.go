@@ -0,0 +1,120 @@
+package mongolib
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"testing"
+)
+
+func openCursors(t *testing.T, db *Database) int64 {
+	var status struct {
+		Metrics struct {
+			Cursor struct {
+				Open struct {
+					Total int64 `bson:"total"`
+				} `bson:"open"`
+			} `bson:"cursor"`
+		} `bson:"metrics"`
+	}
+	err := db.RunCommand(context.Background(), bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status)
+	assert.NoError(t, err)
+	return status.Metrics.Cursor.Open.Total
+}
+
+func TestMultipleResult_Consume(t *testing.T) {
+	const (
+		collName = "coll"
+		// more than the default first batch, so the server keeps a cursor open
+		total = 150
+	)
+	db := initTest(t)
+	var (
+		ctx  = context.Background()
+		coll = db.Coll(collName)
+	)
+
+	tests := []struct {
+		name    string
+		prepare func()
+		action  func()
+		assert  func()
+		wantErr bool
+	}{
+		{
+			name: "success: consume all documents",
+			action: func() {
+				var result []person
+				err := coll.Query().Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Len(t, result, total)
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed: decode error closes cursor",
+			action: func() {
+				var result []struct {
+					Name int `bson:"name"`
+				}
+				err := coll.Query().Find(ctx).Consume(ctx, &result)
+				assert.Error(t, err)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed: callback error closes cursor",
+			action: func() {
+				stop := errors.New("stop")
+				err := coll.Query().Find(ctx).Each(ctx, func(decode func(v interface{}) error) error {
+					return stop
+				})
+				assert.Equal(t, stop, err)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed: empty result with not found",
+			action: func() {
+				var result []person
+				err := coll.Query().Equal("name", "nobody").NotFoundIfEmpty().Find(ctx).Consume(ctx, &result)
+				assert.Equal(t, ErrNotFound, err)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success: empty result without not found",
+			action: func() {
+				var result []person
+				err := coll.Query().Equal("name", "nobody").Find(ctx).Consume(ctx, &result)
+				assert.NoError(t, err)
+				assert.Empty(t, result)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coll.DeleteMany(context.Background(), options.Delete())
+			assert.NoError(t, err)
+			docs := make([]interface{}, 0, total)
+			for i := 0; i < total; i++ {
+				docs = append(docs, person{ID: NewObjectID(), Name: "Trevor", Age: i})
+			}
+			_, err = coll.InsertMany(ctx, docs)
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if tt.action != nil {
+				tt.action()
+			}
+			assert.Equal(t, int64(0), openCursors(t, db))
+			if tt.assert != nil {
+				tt.assert()
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package mongolib
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrUpdateConflict = errors.New("conflicting update paths")
+)
+
+// updateBuilder collects update operators, keeping a single entry per
+// operator so that repeated calls merge instead of producing duplicate keys.
+// Paths that would conflict on the server are reported by document.
+type updateBuilder struct {
+	operators []updateOperator
+	paths     []updatePath
+	pipeline  mongo.Pipeline
+	err       error
+}
+
+type updatePath struct {
+	operator string
+	path     string
+}
+
+type updateOperator struct {
+	name   string
+	fields bson.D
+}
+
+// add returns a copy of u with key set to value under operator op. The
+// receiver is never modified, so that Query values derived from the same
+// base do not share their updates. Repeating an array operator on the same
+// key merges the values instead of conflicting.
+func (u updateBuilder) add(op, key string, value interface{}) updateBuilder {
+	if merged, ok := u.merge(op, key, value); ok {
+		return merged
+	}
+	u = u.touch(op, key)
+	if u.err != nil {
+		return u
+	}
+
+	operators := make([]updateOperator, 0, len(u.operators)+1)
+	found := false
+	for _, o := range u.operators {
+		if o.name == op {
+			fields := make(bson.D, 0, len(o.fields)+1)
+			fields = append(fields, o.fields...)
+			o.fields = append(fields, bson.E{Key: key, Value: value})
+			found = true
+		}
+		operators = append(operators, o)
+	}
+	if !found {
+		operators = append(operators, updateOperator{name: op, fields: bson.D{{Key: key, Value: value}}})
+	}
+	u.operators = operators
+	return u
+}
+
+// merge returns a copy of u with value merged into the existing value of key
+// under operator op, when both are $each or $in lists. It reports false when
+// there is nothing to merge with.
+func (u updateBuilder) merge(op, key string, value interface{}) (updateBuilder, bool) {
+	if u.err != nil {
+		return u, false
+	}
+	for i, o := range u.operators {
+		if o.name != op {
+			continue
+		}
+		for j, field := range o.fields {
+			if field.Key != key {
+				continue
+			}
+			merged, ok := mergeValues(field.Value, value)
+			if !ok {
+				return u, false
+			}
+			fields := make(bson.D, len(o.fields))
+			copy(fields, o.fields)
+			fields[j].Value = merged
+			operators := make([]updateOperator, len(u.operators))
+			copy(operators, u.operators)
+			operators[i].fields = fields
+			u.operators = operators
+			return u, true
+		}
+	}
+	return u, false
+}
+
+// mergeValues appends the $each or $in values of b to those of a, keeping
+// the modifiers of whichever one has them. Lists with modifiers on both
+// sides are not merged.
+func mergeValues(a, b interface{}) (bson.D, bool) {
+	x, ok := a.(bson.D)
+	if !ok || len(x) == 0 {
+		return nil, false
+	}
+	y, ok := b.(bson.D)
+	if !ok || len(y) == 0 || x[0].Key != y[0].Key || (len(x) > 1 && len(y) > 1) {
+		return nil, false
+	}
+	if x[0].Key != "$each" && x[0].Key != "$in" {
+		return nil, false
+	}
+	xs, ok := x[0].Value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	ys, ok := y[0].Value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	values := make([]interface{}, 0, len(xs)+len(ys))
+	values = append(values, xs...)
+	values = append(values, ys...)
+	merged := bson.D{{Key: x[0].Key, Value: values}}
+	merged = append(merged, x[1:]...)
+	return append(merged, y[1:]...), true
+}
+
+// touch records that operator op modifies path, failing if another operator
+// already modifies the same or a nested field.
+func (u updateBuilder) touch(op, path string) updateBuilder {
+	if u.err != nil {
+		return u
+	}
+	for _, p := range u.paths {
+		if conflictingPaths(p.path, path) {
+			u.err = fmt.Errorf("%w: %s %q and %s %q", ErrUpdateConflict, p.operator, p.path, op, path)
+			return u
+		}
+	}
+	paths := make([]updatePath, 0, len(u.paths)+1)
+	paths = append(paths, u.paths...)
+	u.paths = append(paths, updatePath{operator: op, path: path})
+	return u
+}
+
+// fail returns a copy of u reporting err, unless an earlier error is already
+// being reported.
+func (u updateBuilder) fail(err error) updateBuilder {
+	if u.err == nil {
+		u.err = err
+	}
+	return u
+}
+
+// document renders the update document, or the first conflict found while
+// building it. A pipeline update is rendered as the pipeline itself.
+func (u updateBuilder) document() (interface{}, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	if u.pipeline != nil {
+		if len(u.operators) > 0 {
+			return nil, fmt.Errorf("%w: update operators cannot be combined with a pipeline", ErrUpdateConflict)
+		}
+		return u.pipeline, nil
+	}
+	doc := make(bson.D, 0, len(u.operators))
+	for _, o := range u.operators {
+		doc = append(doc, bson.E{Key: o.name, Value: o.fields})
+	}
+	return doc, nil
+}
+
+// conflictingPaths reports whether updating a and b in one operation would
+// touch the same field, either because they are equal or because one is
+// nested inside the other.
+func conflictingPaths(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+// PositionalPath returns the path of fields under the first element of array
+// matched by the query filter, as in "grades.$.score".
+func PositionalPath(array string, fields ...string) string {
+	return strings.Join(append([]string{array, "$"}, fields...), ".")
+}
+
+// AllPositionalPath returns the path of fields under every element of array,
+// as in "grades.$[].score".
+func AllPositionalPath(array string, fields ...string) string {
+	return strings.Join(append([]string{array, "$[]"}, fields...), ".")
+}
+
+// FilteredPositionalPath returns the path of fields under the elements of
+// array matched by the ArrayFilter named identifier, as in
+// "grades.$[g].score".
+func FilteredPositionalPath(array, identifier string, fields ...string) string {
+	return strings.Join(append([]string{array, "$[" + identifier + "]"}, fields...), ".")
+}
+
+// arrayFilter renders filter as an array filter for identifier. Field keys
+// are prefixed with the identifier, and an empty key refers to the element
+// itself.
+func arrayFilter(identifier string, filter Filter) bson.D {
+	return prefixKeys(identifier, filter.document())
+}
+
+func prefixKeys(identifier string, doc bson.D) bson.D {
+	prefixed := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		switch {
+		case e.Key == "$and" || e.Key == "$or" || e.Key == "$nor":
+			e.Value = prefixAll(identifier, e.Value)
+		case strings.HasPrefix(e.Key, "$"):
+		case e.Key == "":
+			e.Key = identifier
+		default:
+			e.Key = identifier + "." + e.Key
+		}
+		prefixed = append(prefixed, e)
+	}
+	return prefixed
+}
+
+// prefixAll prefixes the keys of every document of a logical operator.
+// Conditions that are not a bson.D, such as a bson.M written by hand, are
+// left unprefixed.
+func prefixAll(identifier string, value interface{}) interface{} {
+	conditions, ok := value.(bson.A)
+	if !ok {
+		return value
+	}
+	docs := make(bson.A, 0, len(conditions))
+	for _, c := range conditions {
+		if d, ok := c.(bson.D); ok {
+			c = prefixKeys(identifier, d)
+		}
+		docs = append(docs, c)
+	}
+	return docs
+}
+
+// PushOptions sets the modifiers of a PushWith update. Unset modifiers are
+// left out.
+type PushOptions struct {
+	position *int
+	slice    *int
+	sort     interface{}
+}
+
+func NewPushOptions() *PushOptions {
+	return &PushOptions{}
+}
+
+// SetPosition inserts the values before the given index. A negative index
+// counts from the end of the array.
+func (o *PushOptions) SetPosition(position int) *PushOptions {
+	o.position = &position
+	return o
+}
+
+// SetSlice keeps only the first n elements after the push, or the last -n
+// when n is negative.
+func (o *PushOptions) SetSlice(n int) *PushOptions {
+	o.slice = &n
+	return o
+}
+
+// SetSort sorts the array after the push, either by 1 or -1 for arrays of
+// values, or by a document such as bson.D{{"score", -1}} for subdocuments.
+func (o *PushOptions) SetSort(sort interface{}) *PushOptions {
+	o.sort = sort
+	return o
+}
+
+func (o *PushOptions) modifiers(values []interface{}) bson.D {
+	push := bson.D{{Key: "$each", Value: values}}
+	if o == nil {
+		return push
+	}
+	if o.position != nil {
+		push = append(push, bson.E{Key: "$position", Value: *o.position})
+	}
+	if o.slice != nil {
+		push = append(push, bson.E{Key: "$slice", Value: *o.slice})
+	}
+	if o.sort != nil {
+		push = append(push, bson.E{Key: "$sort", Value: o.sort})
+	}
+	return push
+}
+
+// isNumber reports whether value can be used by arithmetic update operators.
+// Named types are accepted by their underlying kind.
+func isNumber(value interface{}) bool {
+	if _, ok := value.(primitive.Decimal128); ok {
+		return true
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package mongolib
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+)
+
+func TestQuery_UpdateDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   Query
+		want    bson.D
+		wantErr bool
+	}{
+		{
+			name:  "success: merge repeated set",
+			query: Query{}.Set("name", "Ali").Set("car.color", "blue"),
+			want: bson.D{
+				{Key: "$set", Value: bson.D{{Key: "name", Value: "Ali"}, {Key: "car.color", Value: "blue"}}},
+			},
+		},
+		{
+			name:  "success: keep one entry per operator",
+			query: Query{}.Inc("age", 1).Set("name", "Ali").Inc("car.speed", 5),
+			want: bson.D{
+				{Key: "$inc", Value: bson.D{{Key: "age", Value: 1}, {Key: "car.speed", Value: 5}}},
+				{Key: "$set", Value: bson.D{{Key: "name", Value: "Ali"}}},
+			},
+		},
+		{
+			name:    "failed: same path in different operators",
+			query:   Query{}.Set("age", 1).Inc("age", 1),
+			wantErr: true,
+		},
+		{
+			name:    "failed: nested path",
+			query:   Query{}.Set("car", car{}).Set("car.color", "blue"),
+			wantErr: true,
+		},
+		{
+			name:  "success: merge repeated push",
+			query: Query{}.Push("score", 1).Push("score", 2, 3),
+			want: bson.D{
+				{Key: "$push", Value: bson.D{{Key: "score", Value: bson.D{{Key: "$each", Value: []interface{}{1, 2, 3}}}}}},
+			},
+		},
+		{
+			name:  "success: merge repeated add to set and pull",
+			query: Query{}.AddToSet("alias", "a").Pull("score", 1).AddToSet("alias", "b").Pull("score", 2),
+			want: bson.D{
+				{Key: "$addToSet", Value: bson.D{{Key: "alias", Value: bson.D{{Key: "$each", Value: []interface{}{"a", "b"}}}}}},
+				{Key: "$pull", Value: bson.D{{Key: "score", Value: bson.D{{Key: "$in", Value: []interface{}{1, 2}}}}}},
+			},
+		},
+		{
+			name:  "success: merge push keeping modifiers",
+			query: Query{}.PushWith("score", NewPushOptions().SetSlice(-5), 1).Push("score", 2),
+			want: bson.D{
+				{Key: "$push", Value: bson.D{{Key: "score", Value: bson.D{
+					{Key: "$each", Value: []interface{}{1, 2}},
+					{Key: "$slice", Value: -5},
+				}}}},
+			},
+		},
+		{
+			name:    "failed: repeated path in different operators",
+			query:   Query{}.Push("score", 1).Pull("score", 2),
+			wantErr: true,
+		},
+		{
+			name:    "failed: nested path in array operator",
+			query:   Query{}.Push("cars", car{}).AddToSet("cars.0.tags", "a"),
+			wantErr: true,
+		},
+		{
+			name: "success: render each operator",
+			query: Query{}.
+				Unset("alias").
+				SetOnInsert("name", "Ali").
+				Inc("car.speed", int64(2)).
+				Mul("age", 1.5).
+				Min("score.0", 10).
+				Rename("car.color", "car.paint").
+				CurrentDate("updated").
+				AddToSet("tags", "a", "b").
+				PopFirst("queue").
+				PullAll("history", 1, 2).
+				PushWith("cars", NewPushOptions().SetPosition(0).SetSlice(3).SetSort(bson.D{{Key: "speed", Value: -1}}), car{}),
+			want: bson.D{
+				{Key: "$unset", Value: bson.D{{Key: "alias", Value: ""}}},
+				{Key: "$setOnInsert", Value: bson.D{{Key: "name", Value: "Ali"}}},
+				{Key: "$inc", Value: bson.D{{Key: "car.speed", Value: int64(2)}}},
+				{Key: "$mul", Value: bson.D{{Key: "age", Value: 1.5}}},
+				{Key: "$min", Value: bson.D{{Key: "score.0", Value: 10}}},
+				{Key: "$rename", Value: bson.D{{Key: "car.color", Value: "car.paint"}}},
+				{Key: "$currentDate", Value: bson.D{{Key: "updated", Value: true}}},
+				{Key: "$addToSet", Value: bson.D{{Key: "tags", Value: bson.D{{Key: "$each", Value: []interface{}{"a", "b"}}}}}},
+				{Key: "$pop", Value: bson.D{{Key: "queue", Value: -1}}},
+				{Key: "$pullAll", Value: bson.D{{Key: "history", Value: bson.A{1, 2}}}},
+				{Key: "$push", Value: bson.D{{Key: "cars", Value: bson.D{
+					{Key: "$each", Value: []interface{}{car{}}},
+					{Key: "$position", Value: 0},
+					{Key: "$slice", Value: 3},
+					{Key: "$sort", Value: bson.D{{Key: "speed", Value: -1}}},
+				}}}},
+			},
+		},
+		{
+			name:    "failed: rename onto updated path",
+			query:   Query{}.Set("car.paint", "red").Rename("car.color", "car.paint"),
+			wantErr: true,
+		},
+		{
+			name:  "success: sibling paths sharing a prefix",
+			query: Query{}.Set("car", "red").Set("cars", "blue"),
+			want: bson.D{
+				{Key: "$set", Value: bson.D{{Key: "car", Value: "red"}, {Key: "cars", Value: "blue"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.update.document()
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrUpdateConflict))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("success: render pipeline", func(t *testing.T) {
+		got, err := Query{}.UpdatePipeline(NewPipeline().
+			Set("fullName", Concat(Field("first"), " ", Field("last"))).
+			Unset("first", "last")).update.document()
+		assert.NoError(t, err)
+		assert.Equal(t, mongo.Pipeline{
+			{{Key: "$set", Value: bson.D{{Key: "fullName", Value: Concat(Field("first"), " ", Field("last"))}}}},
+			{{Key: "$unset", Value: []string{"first", "last"}}},
+		}, got)
+	})
+
+	t.Run("failed: pipeline with update operators", func(t *testing.T) {
+		_, err := Query{}.Set("age", 1).UpdatePipeline(NewPipeline().Unset("name")).update.document()
+		assert.True(t, errors.Is(err, ErrUpdateConflict))
+	})
+
+	t.Run("failed: increment by non-numeric value", func(t *testing.T) {
+		_, err := Query{}.Inc("age", "1").update.document()
+		assert.Error(t, err)
+	})
+
+	t.Run("success: increment by named numeric type", func(t *testing.T) {
+		type points int
+		got, err := Query{}.Inc("age", points(2)).Mul("car.speed", uint64(3)).update.document()
+		assert.NoError(t, err)
+		assert.Equal(t, bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "age", Value: points(2)}}},
+			{Key: "$mul", Value: bson.D{{Key: "car.speed", Value: uint64(3)}}},
+		}, got)
+	})
+
+	t.Run("success: derived queries do not share updates", func(t *testing.T) {
+		base := Query{}.Set("name", "Ali")
+		a := base.Set("age", 1)
+		b := base.Set("car.color", "blue")
+
+		got, err := a.update.document()
+		assert.NoError(t, err)
+		assert.Equal(t, bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Ali"}, {Key: "age", Value: 1}}}}, got)
+
+		got, err = b.update.document()
+		assert.NoError(t, err)
+		assert.Equal(t, bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Ali"}, {Key: "car.color", Value: "blue"}}}}, got)
+	})
+}
+
+func TestArrayFilter(t *testing.T) {
+	got := arrayFilter("c", NewFilter().
+		Equal("color", "red").
+		Or(NewFilter().GreaterThan("speed", 10), NewFilter().Exists("speed", false)))
+	want := bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "c.color", Value: "red"}},
+		bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "c.speed", Value: bson.D{{Key: "$gt", Value: 10}}}}}}},
+			bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "c.speed", Value: bson.D{{Key: "$exists", Value: false}}}}}}},
+		}}},
+	}}}
+	assert.Equal(t, want, got)
+
+	got = arrayFilter("s", NewFilter().GreaterThan("", 80))
+	assert.Equal(t, bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "s", Value: bson.D{{Key: "$gt", Value: 80}}}}}}}, got)
+
+	manual := bson.M{"score": bson.M{"$gt": 1}}
+	assert.NotPanics(t, func() {
+		got = arrayFilter("s", NewFilter().Or(Filter{manual}))
+	})
+	assert.Equal(t, bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "$or", Value: bson.A{bson.D{{Key: "$and", Value: bson.A{manual}}}}}},
+	}}}, got)
+
+	assert.Equal(t, "cars.$.color", PositionalPath("cars", "color"))
+	assert.Equal(t, "score.$[]", AllPositionalPath("score"))
+	assert.Equal(t, "cars.$[c].speed", FilteredPositionalPath("cars", "c", "speed"))
+}